@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/kafka"
+)
+
+// dlq-replay republishes messages from the dead-letter topic to their original topics.
+//
+//	go run ./cmd/dlq-replay -dry-run
+//	go run ./cmd/dlq-replay -limit 100
+func main() {
+	groupID := flag.String("group", "order-service-dlq-replay", "consumer group tracking replayed letters")
+	limit := flag.Int("limit", 0, "maximum number of letters to replay (0 = all)")
+	idleTimeout := flag.Duration("idle-timeout", 10*time.Second, "stop after no letters arrived for this long")
+	dryRun := flag.Bool("dry-run", false, "print letters without replaying or committing them")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	producer, err := kafka.NewProducer(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to create Kafka producer: %v", err)
+	}
+	defer producer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("🚀 Replaying dead letters from %s", cfg.KafkaTopicDeadLetter)
+	replayed, err := kafka.ReplayDeadLetters(ctx, cfg, producer, kafka.ReplayOptions{
+		GroupID:     *groupID,
+		Limit:       *limit,
+		IdleTimeout: *idleTimeout,
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		log.Printf("❌ Replay stopped after %d letters: %v", replayed, err)
+		return
+	}
+
+	log.Printf("✅ Replayed %d dead letters", replayed)
+}
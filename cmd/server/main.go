@@ -1,46 +1,172 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"order-service/internal/auth"
 	"order-service/internal/config"
 	"order-service/internal/database"
 	"order-service/internal/grpc"
+	"order-service/internal/health"
+	"order-service/internal/http"
 	"order-service/internal/kafka"
+	"order-service/internal/metrics"
+	"order-service/internal/outbox"
 	"order-service/internal/service"
+	"order-service/internal/tracing"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
+	if err := run(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// run starts the service and blocks until it is told to stop or a server fails.
+// Startup errors are returned rather than fatal so the deferred cleanup still runs.
+func run() error {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("❌ Failed to load config: %v", err)
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("❌ Failed to flush traces: %v", err)
+		}
+	}()
 
 	// Initialize database
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize database: %v", err)
+		return fmt.Errorf("failed to initialize database: %w", err)
 	}
 	defer db.Close()
 	log.Println("✅ Database connected successfully")
+	prometheus.MustRegister(metrics.NewPoolCollector(db.Pool))
 
 	// Initialize Kafka producer
-	producer, err := kafka.NewProducer(cfg.KafkaBrokers)
+	producer, err := kafka.NewProducer(cfg)
 	if err != nil {
-		log.Fatalf("❌ Failed to create Kafka producer: %v", err)
+		return fmt.Errorf("failed to create Kafka producer: %w", err)
 	}
 	defer producer.Close()
 	log.Println("✅ Kafka producer connected")
 
+	// Start dependency health checks
+	checker := health.NewChecker(db, producer, cfg.HealthCheckInterval, cfg.HealthCheckTimeout)
+	checker.Start(context.Background())
+	defer checker.Stop()
+
+	// Start outbox relay
+	relay := outbox.NewRelay(db, producer, cfg)
+	relay.Start(context.Background())
+	defer relay.Stop()
+
 	// Initialize service
-	orderService := service.NewOrderService(db, producer, cfg.KafkaTopicOrderCreated)
+	orderService := service.NewOrderService(db, cfg)
+
+	// Start idempotency key janitor
+	janitor := service.NewIdempotencyJanitor(db, cfg.IdempotencyPurgeInterval)
+	janitor.Start(context.Background())
+	defer janitor.Stop()
+
+	// Start processed events janitor
+	eventsJanitor := service.NewProcessedEventsJanitor(db, cfg.ProcessedEventsRetention, cfg.ProcessedEventsPurgeInterval)
+	eventsJanitor.Start(context.Background())
+	defer eventsJanitor.Stop()
+
+	// Start payment events consumer
+	if cfg.KafkaConsumerEnabled {
+		consumer, err := kafka.NewConsumer(cfg, cfg.KafkaConsumerGroupID, cfg.KafkaPaymentTopics, orderService.HandlePaymentMessage, producer)
+		if err != nil {
+			return fmt.Errorf("failed to create Kafka consumer: %w", err)
+		}
+		consumer.Start(context.Background())
+		defer consumer.Stop()
+	}
 
 	// Initialize gRPC handler
 	orderHandler := grpc.NewOrderGrpcHandler(orderService)
 
+	// Initialize token verifier
+	var verifier *auth.Verifier
+	if cfg.AuthEnabled {
+		verifier, err = auth.NewVerifier(context.Background(), cfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize authentication: %w", err)
+		}
+		log.Println("✅ Authentication enabled")
+	} else {
+		log.Println("⚠️ Authentication disabled; every caller can access every order")
+	}
+
 	// Start gRPC server
+	server, err := grpc.NewServer(cfg, orderHandler, verifier, checker.Server())
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server: %w", err)
+	}
+
+	// Start HTTP server for health probes
+	httpServer := http.NewServer(cfg.HTTPPort, checker)
+
 	log.Println("🚀 Starting Order Service...")
-	if err := grpc.StartGRPCServer(cfg.GRPCPort, orderHandler); err != nil {
-		log.Fatalf("❌ Failed to start gRPC server: %v", err)
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- server.Serve()
+	}()
+	go func() {
+		if err := httpServer.Serve(); err != nil {
+			serveErr <- fmt.Errorf("http: %w", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Println("🛑 Shutting down Order Service...")
+	case err := <-serveErr:
+		// Logged by main once everything is shut down
+		runErr = fmt.Errorf("server failed: %w", err)
+		log.Println("🛑 Server failed; shutting down Order Service...")
+	}
+	// A second signal terminates immediately
+	stop()
+
+	// Fail readiness and give load balancers time to notice and stop routing before
+	// draining RPCs; the deferred calls then stop the consumer, janitors, relay and
+	// health checks, flush the Kafka producer and close the database pool, in that order
+	checker.Shutdown()
+	if cfg.ShutdownDrainDelay > 0 {
+		log.Printf("⏳ Waiting %s for load balancers to stop routing", cfg.ShutdownDrainDelay)
+		time.Sleep(cfg.ShutdownDrainDelay)
 	}
+
+	grpcCtx, cancelGRPC := context.WithTimeout(context.Background(), cfg.GRPCShutdownTimeout)
+	defer cancelGRPC()
+	server.Shutdown(grpcCtx)
+
+	// The HTTP server keeps answering probes until the RPCs are drained
+	httpCtx, cancelHTTP := context.WithTimeout(context.Background(), cfg.HTTPShutdownTimeout)
+	defer cancelHTTP()
+	httpServer.Shutdown(httpCtx)
+
+	return runErr
 }
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"slices"
+)
+
+// ClientIdentity is the identity presented in a verified mTLS client certificate
+type ClientIdentity struct {
+	CommonName string
+	DNSNames   []string
+	// URIs holds URI SANs such as SPIFFE IDs
+	URIs []string
+}
+
+// NewClientIdentity extracts the subject CN and SANs of cert
+func NewClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	identity := &ClientIdentity{
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
+
+// Matches reports whether the CN or any SAN of the identity is in names
+func (c *ClientIdentity) Matches(names map[string]bool) bool {
+	if c.CommonName != "" && names[c.CommonName] {
+		return true
+	}
+	return slices.ContainsFunc(c.DNSNames, func(n string) bool { return names[n] }) ||
+		slices.ContainsFunc(c.URIs, func(n string) bool { return names[n] })
+}
+
+// String returns the most specific name of the identity, for logs
+func (c *ClientIdentity) String() string {
+	switch {
+	case len(c.URIs) > 0:
+		return c.URIs[0]
+	case c.CommonName != "":
+		return c.CommonName
+	case len(c.DNSNames) > 0:
+		return c.DNSNames[0]
+	}
+	return "unknown"
+}
+
+type clientIdentityKey struct{}
+
+// WithClientIdentity returns a copy of ctx carrying the mTLS client identity
+func WithClientIdentity(ctx context.Context, identity *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, identity)
+}
+
+// ClientIdentityFromContext returns the mTLS client identity carried by ctx, or nil
+// when the connection did not present a verified certificate
+func ClientIdentityFromContext(ctx context.Context) *ClientIdentity {
+	identity, _ := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return identity
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Roles granted by access tokens
+const (
+	// RoleCustomer may only access the caller's own orders
+	RoleCustomer = "customer"
+	// RoleAdmin and RoleService may access every order
+	RoleAdmin   = "admin"
+	RoleService = "service"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject is the token's sub claim
+	Subject string
+	// UserID is the customer the caller acts as; zero when the token carries none
+	UserID int32
+	Roles  []string
+}
+
+// HasRole reports whether the principal was granted role
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// IsPrivileged reports whether the principal may access every user's orders
+func (p *Principal) IsPrivileged() bool {
+	return p.HasRole(RoleAdmin) || p.HasRole(RoleService)
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal carried by ctx, or nil for unauthenticated
+// internal calls
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
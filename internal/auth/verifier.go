@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+
+	"order-service/internal/config"
+)
+
+// signingMethods are the asymmetric algorithms accepted for access tokens. HMAC is
+// excluded so a public JWKS key can never be used as a shared secret.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Verifier validates access tokens against a JWKS and turns them into principals
+type Verifier struct {
+	keyfunc     jwt.Keyfunc
+	parser      *jwt.Parser
+	userIDClaim string
+	rolesClaim  string
+}
+
+// NewVerifier loads the JWKS from cfg.AuthJWKSFile or cfg.AuthJWKSURL. A URL is
+// refreshed in the background until ctx is done.
+func NewVerifier(ctx context.Context, cfg *config.Config) (*Verifier, error) {
+	var (
+		keys keyfunc.Keyfunc
+		err  error
+	)
+	switch {
+	case cfg.AuthJWKSFile != "":
+		raw, readErr := os.ReadFile(cfg.AuthJWKSFile)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", readErr)
+		}
+		keys, err = keyfunc.NewJWKSetJSON(json.RawMessage(raw))
+	case cfg.AuthJWKSURL != "":
+		keys, err = keyfunc.NewDefaultCtx(ctx, []string{cfg.AuthJWKSURL})
+	default:
+		return nil, fmt.Errorf("AUTH_JWKS_FILE or AUTH_JWKS_URL is required when authentication is enabled")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.AuthIssuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.AuthIssuer))
+	}
+	if cfg.AuthAudience != "" {
+		options = append(options, jwt.WithAudience(cfg.AuthAudience))
+	}
+
+	return &Verifier{
+		keyfunc:     keys.Keyfunc,
+		parser:      jwt.NewParser(options...),
+		userIDClaim: cfg.AuthUserIDClaim,
+		rolesClaim:  cfg.AuthRolesClaim,
+	}, nil
+}
+
+// Verify checks the token's signature, expiry, issuer and audience and returns
+// the principal it describes
+func (v *Verifier) Verify(tokenString string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyfunc); err != nil {
+		return nil, err
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		Subject: subject,
+		Roles:   rolesClaim(claims[v.rolesClaim]),
+	}
+	if len(principal.Roles) == 0 {
+		return nil, fmt.Errorf("token has no %s claim", v.rolesClaim)
+	}
+
+	// Service tokens usually carry a non-numeric subject; only customers need a user ID
+	userID, err := userIDClaim(claims[v.userIDClaim])
+	if !principal.IsPrivileged() {
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s claim: %w", v.userIDClaim, err)
+		}
+		if userID == 0 {
+			return nil, fmt.Errorf("customer token has no %s claim", v.userIDClaim)
+		}
+	}
+	principal.UserID = userID
+
+	return principal, nil
+}
+
+// rolesClaim accepts either a JSON array or a space-separated string, as used by
+// OAuth scope claims
+func rolesClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, role := range v {
+			if s, ok := role.(string); ok && s != "" {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
+
+// userIDClaim accepts a numeric claim or a numeric string; a missing claim is zero
+func userIDClaim(value interface{}) (int32, error) {
+	var (
+		id  int64
+		err error
+	)
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		id = int64(v)
+		if float64(id) != v {
+			return 0, fmt.Errorf("not an integer")
+		}
+	case string:
+		id, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unexpected type %T", value)
+	}
+	if id <= 0 || id > 1<<31-1 {
+		return 0, fmt.Errorf("out of range")
+	}
+	return int32(id), nil
+}
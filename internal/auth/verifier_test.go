@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestUserIDClaim(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int32
+		wantErr bool
+	}{
+		{name: "missing", value: nil, want: 0},
+		{name: "number", value: float64(42), want: 42},
+		{name: "numeric string", value: "42", want: 42},
+		{name: "max int32", value: float64(math.MaxInt32), want: math.MaxInt32},
+		{name: "fraction", value: 4.2, wantErr: true},
+		{name: "zero", value: float64(0), wantErr: true},
+		{name: "negative", value: float64(-1), wantErr: true},
+		{name: "negative string", value: "-1", wantErr: true},
+		{name: "above int32", value: float64(math.MaxInt32 + 1), wantErr: true},
+		{name: "string above int32", value: "2147483648", wantErr: true},
+		{name: "huge number", value: 1e20, wantErr: true},
+		{name: "not a number", value: "abc", wantErr: true},
+		{name: "boolean", value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := userIDClaim(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("userIDClaim(%v) = %d, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("userIDClaim(%v) = %d, %v, want %d", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolesClaim(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{name: "missing", value: nil, want: nil},
+		{name: "space separated", value: "admin  support", want: []string{"admin", "support"}},
+		{name: "empty string", value: "", want: []string{}},
+		{name: "list", value: []interface{}{"admin", "support"}, want: []string{"admin", "support"}},
+		{name: "list skips non-strings and empty roles", value: []interface{}{"admin", 7, "", nil}, want: []string{"admin"}},
+		{name: "unexpected type", value: float64(1), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rolesClaim(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rolesClaim(%v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,17 +4,107 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
+	// Service
+	ServiceName string
+
 	// gRPC Server
 	GRPCPort int
+	// LegacyErrorEnvelope returns errors as OK responses with Success=false instead of gRPC status codes.
+	// On by default until every client handles status codes.
+	LegacyErrorEnvelope bool
+	// GRPCShutdownTimeout bounds how long in-flight RPCs may run after SIGTERM
+	GRPCShutdownTimeout time.Duration
+	// ShutdownDrainDelay is how long the service keeps serving after readiness fails
+	// on SIGTERM, so load balancers stop routing before RPCs are drained
+	ShutdownDrainDelay time.Duration
+
+	// HTTP server for metrics and health probes
+	HTTPPort            int
+	HTTPShutdownTimeout time.Duration
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+
+	// gRPC TLS
+	GRPCTLSEnabled        bool
+	GRPCTLSCertFile       string
+	GRPCTLSKeyFile        string
+	GRPCTLSClientCAFile   string
+	GRPCTLSReloadInterval time.Duration
+	GRPCAllowedClients    []string
+	// GRPCAllowListedMethods are the RPCs only GRPCAllowedClients may call
+	GRPCAllowListedMethods []string
+
+	// Authentication
+	AuthEnabled     bool
+	AuthJWKSFile    string
+	AuthJWKSURL     string
+	AuthIssuer      string
+	AuthAudience    string
+	AuthUserIDClaim string
+	AuthRolesClaim  string
 
 	// Kafka
-	KafkaBrokers           string
-	KafkaTopicOrderCreated string
+	KafkaBrokers                 []string
+	KafkaTopicOrderCreated       string
+	KafkaTopicOrderStatusChanged string
+	KafkaTopicOrderCancelled     string
+	KafkaTopicOrderShipped       string
+	KafkaTopicOrderDelivered     string
+	KafkaTopicOrderRefunded      string
+	KafkaTopicDeadLetter         string
+
+	// Kafka consumer
+	KafkaConsumerEnabled bool
+	KafkaConsumerGroupID string
+	KafkaPaymentTopics   []string
+	// ProcessedEventsRetention is how long consumed event IDs are kept for deduplication
+	ProcessedEventsRetention     time.Duration
+	ProcessedEventsPurgeInterval time.Duration
+
+	// Kafka consumer retries
+	KafkaConsumerMaxAttempts         int
+	KafkaConsumerRetryInitialBackoff time.Duration
+	KafkaConsumerRetryMaxBackoff     time.Duration
+
+	// Kafka connection
+	KafkaTLSEnabled            bool
+	KafkaTLSCAFile             string
+	KafkaTLSCertFile           string
+	KafkaTLSKeyFile            string
+	KafkaTLSInsecureSkipVerify bool
+	KafkaSASLMechanism         string
+	KafkaSASLUsername          string
+	KafkaSASLPassword          string
+	KafkaCompression           string
+	KafkaBatchSize             int
+	KafkaBatchTimeout          time.Duration
+
+	// Outbox relay
+	OutboxPollInterval        time.Duration
+	OutboxBatchSize           int
+	OutboxMaxAttempts         int
+	OutboxRetryInitialBackoff time.Duration
+	OutboxRetryMaxBackoff     time.Duration
+
+	// Orders
+	DefaultCurrency   string
+	IgnoreClientTotal bool
+
+	// Idempotency
+	IdempotencyKeyTTL        time.Duration
+	IdempotencyPurgeInterval time.Duration
+
+	// Tracing
+	TracingExporter    string
+	TracingFile        string
+	TracingSampleRatio float64
 
 	// Database
 	DatabaseURL string
@@ -31,12 +121,91 @@ func Load() (*Config, error) {
 
 	config := &Config{}
 
+	// Service
+	config.ServiceName = getEnv("SERVICE_NAME", "order-service")
+
 	// gRPC Server
 	config.GRPCPort = getEnvAsInt("GRPC_PORT", 5001)
+	config.LegacyErrorEnvelope = getEnvAsBool("GRPC_LEGACY_ERROR_ENVELOPE", true)
+	config.GRPCShutdownTimeout = getEnvAsDuration("GRPC_SHUTDOWN_TIMEOUT", 20*time.Second)
+	config.ShutdownDrainDelay = getEnvAsDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second)
+
+	// HTTP server for metrics and health probes
+	config.HTTPPort = getEnvAsInt("HTTP_PORT", 8080)
+	config.HTTPShutdownTimeout = getEnvAsDuration("HTTP_SHUTDOWN_TIMEOUT", 3*time.Second)
+	config.HealthCheckInterval = getEnvAsDuration("HEALTH_CHECK_INTERVAL", 10*time.Second)
+	config.HealthCheckTimeout = getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+
+	// gRPC TLS
+	config.GRPCTLSEnabled = getEnvAsBool("GRPC_TLS_ENABLED", false)
+	config.GRPCTLSCertFile = getEnv("GRPC_TLS_CERT_FILE", "")
+	config.GRPCTLSKeyFile = getEnv("GRPC_TLS_KEY_FILE", "")
+	config.GRPCTLSClientCAFile = getEnv("GRPC_TLS_CLIENT_CA_FILE", "")
+	config.GRPCTLSReloadInterval = getEnvAsDuration("GRPC_TLS_RELOAD_INTERVAL", 30*time.Second)
+	config.GRPCAllowedClients = getEnvAsList("GRPC_ALLOWED_CLIENTS", nil)
+	config.GRPCAllowListedMethods = getEnvAsList("GRPC_ALLOW_LISTED_METHODS", []string{"UpdateOrderStatus", "CancelOrder", "CreateRefund", "UpdateRefundStatus"})
+
+	// Authentication
+	config.AuthEnabled = getEnvAsBool("AUTH_ENABLED", false)
+	config.AuthJWKSFile = getEnv("AUTH_JWKS_FILE", "")
+	config.AuthJWKSURL = getEnv("AUTH_JWKS_URL", "")
+	config.AuthIssuer = getEnv("AUTH_ISSUER", "")
+	config.AuthAudience = getEnv("AUTH_AUDIENCE", "")
+	config.AuthUserIDClaim = getEnv("AUTH_USER_ID_CLAIM", "sub")
+	config.AuthRolesClaim = getEnv("AUTH_ROLES_CLAIM", "roles")
 
 	// Kafka
-	config.KafkaBrokers = getEnv("KAFKA_BROKERS", "localhost:9092")
+	config.KafkaBrokers = getEnvAsList("KAFKA_BROKERS", []string{"localhost:9092"})
 	config.KafkaTopicOrderCreated = getEnv("KAFKA_TOPIC_ORDER_CREATED", "order.created")
+	config.KafkaTopicOrderStatusChanged = getEnv("KAFKA_TOPIC_ORDER_STATUS_CHANGED", "order.status_changed")
+	config.KafkaTopicOrderCancelled = getEnv("KAFKA_TOPIC_ORDER_CANCELLED", "order.cancelled")
+	config.KafkaTopicOrderShipped = getEnv("KAFKA_TOPIC_ORDER_SHIPPED", "order.shipped")
+	config.KafkaTopicOrderDelivered = getEnv("KAFKA_TOPIC_ORDER_DELIVERED", "order.delivered")
+	config.KafkaTopicOrderRefunded = getEnv("KAFKA_TOPIC_ORDER_REFUNDED", "order.refunded")
+	config.KafkaTopicDeadLetter = getEnv("KAFKA_TOPIC_DEAD_LETTER", "order-service.dlq")
+
+	// Kafka consumer
+	config.KafkaConsumerEnabled = getEnvAsBool("KAFKA_CONSUMER_ENABLED", false)
+	config.KafkaConsumerGroupID = getEnv("KAFKA_CONSUMER_GROUP_ID", "order-service")
+	config.KafkaPaymentTopics = getEnvAsList("KAFKA_PAYMENT_TOPICS", []string{"payment.succeeded", "payment.failed"})
+	config.ProcessedEventsRetention = getEnvAsDuration("PROCESSED_EVENTS_RETENTION", 7*24*time.Hour)
+	config.ProcessedEventsPurgeInterval = getEnvAsDuration("PROCESSED_EVENTS_PURGE_INTERVAL", time.Hour)
+	config.KafkaConsumerMaxAttempts = getEnvAsInt("KAFKA_CONSUMER_MAX_ATTEMPTS", 5)
+	config.KafkaConsumerRetryInitialBackoff = getEnvAsDuration("KAFKA_CONSUMER_RETRY_INITIAL_BACKOFF", time.Second)
+	config.KafkaConsumerRetryMaxBackoff = getEnvAsDuration("KAFKA_CONSUMER_RETRY_MAX_BACKOFF", 30*time.Second)
+
+	// Kafka connection
+	config.KafkaTLSEnabled = getEnvAsBool("KAFKA_TLS_ENABLED", false)
+	config.KafkaTLSCAFile = getEnv("KAFKA_TLS_CA_FILE", "")
+	config.KafkaTLSCertFile = getEnv("KAFKA_TLS_CERT_FILE", "")
+	config.KafkaTLSKeyFile = getEnv("KAFKA_TLS_KEY_FILE", "")
+	config.KafkaTLSInsecureSkipVerify = getEnvAsBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false)
+	config.KafkaSASLMechanism = getEnv("KAFKA_SASL_MECHANISM", "") // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	config.KafkaSASLUsername = getEnv("KAFKA_SASL_USERNAME", "")
+	config.KafkaSASLPassword = getEnv("KAFKA_SASL_PASSWORD", "")
+	config.KafkaCompression = getEnv("KAFKA_COMPRESSION", "none") // none, gzip, snappy, lz4 or zstd
+	config.KafkaBatchSize = getEnvAsInt("KAFKA_BATCH_SIZE", 100)
+	config.KafkaBatchTimeout = getEnvAsDuration("KAFKA_BATCH_TIMEOUT", 10*time.Millisecond)
+
+	// Outbox relay
+	config.OutboxPollInterval = getEnvAsDuration("OUTBOX_POLL_INTERVAL", 500*time.Millisecond)
+	config.OutboxBatchSize = getEnvAsInt("OUTBOX_BATCH_SIZE", 100)
+	config.OutboxMaxAttempts = getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10)
+	config.OutboxRetryInitialBackoff = getEnvAsDuration("OUTBOX_RETRY_INITIAL_BACKOFF", time.Second)
+	config.OutboxRetryMaxBackoff = getEnvAsDuration("OUTBOX_RETRY_MAX_BACKOFF", 5*time.Minute)
+
+	// Orders
+	config.DefaultCurrency = getEnv("DEFAULT_CURRENCY", "USD")
+	config.IgnoreClientTotal = getEnvAsBool("ORDER_IGNORE_CLIENT_TOTAL", false)
+
+	// Idempotency
+	config.IdempotencyKeyTTL = getEnvAsDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	config.IdempotencyPurgeInterval = getEnvAsDuration("IDEMPOTENCY_PURGE_INTERVAL", time.Hour)
+
+	// Tracing
+	config.TracingExporter = getEnv("TRACING_EXPORTER", "none") // none, otlp or stdout
+	config.TracingFile = getEnv("TRACING_FILE", "")
+	config.TracingSampleRatio = getEnvAsFloat("TRACING_SAMPLE_RATIO", 1)
 
 	// Database
 	config.DatabaseURL = getEnv("DATABASE_URL", "")
@@ -54,9 +223,65 @@ func Load() (*Config, error) {
 		)
 	}
 
+	if err := config.validateDurations(); err != nil {
+		return nil, err
+	}
+	if err := config.validateSizes(); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
+// validateDurations rejects non-positive intervals and timeouts. Intervals drive
+// tickers, which panic on a zero or negative period.
+func (c *Config) validateDurations() error {
+	durations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"GRPC_SHUTDOWN_TIMEOUT", c.GRPCShutdownTimeout},
+		{"HTTP_SHUTDOWN_TIMEOUT", c.HTTPShutdownTimeout},
+		{"HEALTH_CHECK_INTERVAL", c.HealthCheckInterval},
+		{"HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout},
+		{"GRPC_TLS_RELOAD_INTERVAL", c.GRPCTLSReloadInterval},
+		{"OUTBOX_POLL_INTERVAL", c.OutboxPollInterval},
+		{"IDEMPOTENCY_KEY_TTL", c.IdempotencyKeyTTL},
+		{"IDEMPOTENCY_PURGE_INTERVAL", c.IdempotencyPurgeInterval},
+		{"PROCESSED_EVENTS_RETENTION", c.ProcessedEventsRetention},
+		{"PROCESSED_EVENTS_PURGE_INTERVAL", c.ProcessedEventsPurgeInterval},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("%s must be a positive duration, got %s", d.key, d.value)
+		}
+	}
+	// Zero skips the drain delay
+	if c.ShutdownDrainDelay < 0 {
+		return fmt.Errorf("SHUTDOWN_DRAIN_DELAY cannot be negative, got %s", c.ShutdownDrainDelay)
+	}
+	return nil
+}
+
+// validateSizes rejects non-positive batch sizes and attempt limits. A zero outbox
+// batch claims no rows, so the relay would keep polling without ever sleeping.
+func (c *Config) validateSizes() error {
+	sizes := []struct {
+		key   string
+		value int
+	}{
+		{"KAFKA_BATCH_SIZE", c.KafkaBatchSize},
+		{"OUTBOX_BATCH_SIZE", c.OutboxBatchSize},
+		{"OUTBOX_MAX_ATTEMPTS", c.OutboxMaxAttempts},
+	}
+	for _, s := range sizes {
+		if s.value <= 0 {
+			return fmt.Errorf("%s must be a positive number, got %d", s.key, s.value)
+		}
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -71,3 +296,41 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsList splits a comma-separated value, dropping empty entries
+func getEnvAsList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnv(key, "")
+	if value, err := time.ParseDuration(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadRejectsNonPositiveDurations(t *testing.T) {
+	keys := []string{
+		"GRPC_SHUTDOWN_TIMEOUT",
+		"HTTP_SHUTDOWN_TIMEOUT",
+		"OUTBOX_POLL_INTERVAL",
+		"IDEMPOTENCY_PURGE_INTERVAL",
+		"HEALTH_CHECK_INTERVAL",
+		"GRPC_TLS_RELOAD_INTERVAL",
+	}
+
+	for _, key := range keys {
+		for _, value := range []string{"0s", "-1s"} {
+			t.Run(key+"="+value, func(t *testing.T) {
+				t.Setenv(key, value)
+
+				_, err := Load()
+				if err == nil || !strings.Contains(err.Error(), key) {
+					t.Fatalf("Load() error = %v, want an error naming %s", err, key)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadRejectsNonPositiveSizes(t *testing.T) {
+	keys := []string{
+		"KAFKA_BATCH_SIZE",
+		"OUTBOX_BATCH_SIZE",
+		"OUTBOX_MAX_ATTEMPTS",
+	}
+
+	for _, key := range keys {
+		for _, value := range []string{"0", "-1"} {
+			t.Run(key+"="+value, func(t *testing.T) {
+				t.Setenv(key, value)
+
+				_, err := Load()
+				if err == nil || !strings.Contains(err.Error(), key) {
+					t.Fatalf("Load() error = %v, want an error naming %s", err, key)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadShutdownDrainDelay(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "0s", want: 0},
+		{value: "10s", want: 10 * time.Second},
+		{value: "-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("SHUTDOWN_DRAIN_DELAY", tt.value)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "SHUTDOWN_DRAIN_DELAY") {
+					t.Fatalf("Load() error = %v, want an error naming SHUTDOWN_DRAIN_DELAY", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.ShutdownDrainDelay != tt.want {
+				t.Errorf("ShutdownDrainDelay = %s, want %s", cfg.ShutdownDrainDelay, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.OutboxPollInterval <= 0 || cfg.IdempotencyPurgeInterval <= 0 ||
+		cfg.HealthCheckInterval <= 0 || cfg.GRPCTLSReloadInterval <= 0 {
+		t.Errorf("Load() returned non-positive default intervals: %+v", cfg)
+	}
+	if cfg.KafkaBatchSize <= 0 || cfg.OutboxBatchSize <= 0 || cfg.OutboxMaxAttempts <= 0 {
+		t.Errorf("Load() returned non-positive default sizes: %+v", cfg)
+	}
+	if !slices.Contains(cfg.GRPCAllowListedMethods, "CancelOrder") {
+		t.Errorf("Load() GRPCAllowListedMethods = %v, want CancelOrder restricted by default", cfg.GRPCAllowListedMethods)
+	}
+	if total := cfg.ShutdownDrainDelay + cfg.GRPCShutdownTimeout + cfg.HTTPShutdownTimeout; total >= 30*time.Second {
+		t.Errorf("Load() default shutdown takes up to %s, want it below the 30s ECS stop timeout", total)
+	}
+	if !cfg.LegacyErrorEnvelope {
+		t.Errorf("Load() LegacyErrorEnvelope = false, want the legacy envelope on by default")
+	}
+}
@@ -0,0 +1,31 @@
+package correlation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header is the metadata key used to propagate a correlation ID between services
+const Header = "x-correlation-id"
+
+// RequestIDHeader is accepted as a fallback when no correlation ID is sent
+const RequestIDHeader = "x-request-id"
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx carrying the correlation ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the correlation ID carried by ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// NewID generates a new correlation ID
+func NewID() string {
+	return uuid.NewString()
+}
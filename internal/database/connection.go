@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"order-service/internal/database/db"
+	"order-service/internal/tracing"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DB struct {
@@ -15,7 +18,13 @@ type DB struct {
 }
 
 func NewConnection(databaseURL string) (*DB, error) {
-	pool, err := pgxpool.New(context.Background(), databaseURL)
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -31,6 +40,19 @@ func NewConnection(databaseURL string) (*DB, error) {
 	}, nil
 }
 
+// Begin starts a transaction traced as a single span around its statements
+func (db *DB) Begin(ctx context.Context) (pgx.Tx, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.transaction", trace.WithSpanKind(trace.SpanKindClient))
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+
+	return &tracedTx{Tx: tx, span: span}, nil
+}
+
 func (db *DB) Close() {
 	db.Pool.Close()
 	log.Println("✅ Database connection closed")
@@ -8,21 +8,101 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type IdempotencyKey struct {
+	ID             int32            `json:"id"`
+	UserID         int32            `json:"user_id"`
+	IdempotencyKey string           `json:"idempotency_key"`
+	RequestHash    string           `json:"request_hash"`
+	OrderID        pgtype.Int4      `json:"order_id"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
 type Order struct {
-	ID          int32            `json:"id"`
-	UserID      int32            `json:"user_id"`
-	Status      string           `json:"status"`
-	TotalAmount float64          `json:"total_amount"`
-	CreatedAt   pgtype.Timestamp `json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	ID               int32            `json:"id"`
+	UserID           int32            `json:"user_id"`
+	Status           string           `json:"status"`
+	TotalAmountMinor int64            `json:"total_amount_minor"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+	Currency         string           `json:"currency"`
+	Version          int32            `json:"version"`
+	PaidAmountMinor  int64            `json:"paid_amount_minor"`
+}
+
+type OrderCancellation struct {
+	ID             int32            `json:"id"`
+	OrderID        int32            `json:"order_id"`
+	PreviousStatus string           `json:"previous_status"`
+	ReasonCode     string           `json:"reason_code"`
+	Note           pgtype.Text      `json:"note"`
+	Actor          string           `json:"actor"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
 }
 
 type OrderProduct struct {
-	ID        int32            `json:"id"`
-	OrderID   int32            `json:"order_id"`
-	ProductID int32            `json:"product_id"`
-	Quantity  int32            `json:"quantity"`
-	Price     float64          `json:"price"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	ID         int32            `json:"id"`
+	OrderID    int32            `json:"order_id"`
+	ProductID  int32            `json:"product_id"`
+	Quantity   int32            `json:"quantity"`
+	PriceMinor int64            `json:"price_minor"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
+}
+
+type OrderStatusHistory struct {
+	ID         int64            `json:"id"`
+	OrderID    int32            `json:"order_id"`
+	FromStatus pgtype.Text      `json:"from_status"`
+	ToStatus   string           `json:"to_status"`
+	Actor      string           `json:"actor"`
+	Source     string           `json:"source"`
+	Reason     pgtype.Text      `json:"reason"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Outbox struct {
+	ID            int64            `json:"id"`
+	AggregateID   int32            `json:"aggregate_id"`
+	Topic         string           `json:"topic"`
+	Payload       []byte           `json:"payload"`
+	Status        string           `json:"status"`
+	Attempts      int32            `json:"attempts"`
+	LastError     pgtype.Text      `json:"last_error"`
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	SentAt        pgtype.Timestamp `json:"sent_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+	EventID       pgtype.UUID      `json:"event_id"`
+	MessageKey    string           `json:"message_key"`
+	Headers       []byte           `json:"headers"`
+	LockedUntil   pgtype.Timestamp `json:"locked_until"`
+}
+
+type ProcessedEvent struct {
+	EventID     string           `json:"event_id"`
+	Topic       string           `json:"topic"`
+	ProcessedAt pgtype.Timestamp `json:"processed_at"`
+}
+
+type Refund struct {
+	ID            int32            `json:"id"`
+	OrderID       int32            `json:"order_id"`
+	Status        string           `json:"status"`
+	AmountMinor   int64            `json:"amount_minor"`
+	Currency      string           `json:"currency"`
+	Reason        string           `json:"reason"`
+	Actor         string           `json:"actor"`
+	FailureReason pgtype.Text      `json:"failure_reason"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type RefundItem struct {
+	ID             int32            `json:"id"`
+	RefundID       int32            `json:"refund_id"`
+	OrderProductID int32            `json:"order_product_id"`
+	Quantity       int32            `json:"quantity"`
+	AmountMinor    int64            `json:"amount_minor"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
 }
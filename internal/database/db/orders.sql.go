@@ -11,43 +11,83 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const bumpOrderVersion = `-- name: BumpOrderVersion :one
+UPDATE orders
+SET version = version + 1
+WHERE id = $1 AND version = $2
+RETURNING id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor
+`
+
+type BumpOrderVersionParams struct {
+	ID      int32 `json:"id"`
+	Version int32 `json:"version"`
+}
+
+// Records a change that keeps the status. Returns no rows when the order is not at
+// the expected version.
+func (q *Queries) BumpOrderVersion(ctx context.Context, arg BumpOrderVersionParams) (Order, error) {
+	row := q.db.QueryRow(ctx, bumpOrderVersion, arg.ID, arg.Version)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalAmountMinor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.PaidAmountMinor,
+	)
+	return i, err
+}
+
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (user_id, status, total_amount)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, status, total_amount, created_at, updated_at
+INSERT INTO orders (user_id, status, total_amount_minor, currency)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor
 `
 
 type CreateOrderParams struct {
-	UserID      int32   `json:"user_id"`
-	Status      string  `json:"status"`
-	TotalAmount float64 `json:"total_amount"`
+	UserID           int32  `json:"user_id"`
+	Status           string `json:"status"`
+	TotalAmountMinor int64  `json:"total_amount_minor"`
+	Currency         string `json:"currency"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
-	row := q.db.QueryRow(ctx, createOrder, arg.UserID, arg.Status, arg.TotalAmount)
+	row := q.db.QueryRow(ctx, createOrder,
+		arg.UserID,
+		arg.Status,
+		arg.TotalAmountMinor,
+		arg.Currency,
+	)
 	var i Order
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.Status,
-		&i.TotalAmount,
+		&i.TotalAmountMinor,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.PaidAmountMinor,
 	)
 	return i, err
 }
 
 const createOrderProduct = `-- name: CreateOrderProduct :one
-INSERT INTO order_products (order_id, product_id, quantity, price)
+INSERT INTO order_products (order_id, product_id, quantity, price_minor)
 VALUES ($1, $2, $3, $4)
-RETURNING id, order_id, product_id, quantity, price, created_at, updated_at
+RETURNING id, order_id, product_id, quantity, price_minor, created_at, updated_at
 `
 
 type CreateOrderProductParams struct {
-	OrderID   int32   `json:"order_id"`
-	ProductID int32   `json:"product_id"`
-	Quantity  int32   `json:"quantity"`
-	Price     float64 `json:"price"`
+	OrderID    int32 `json:"order_id"`
+	ProductID  int32 `json:"product_id"`
+	Quantity   int32 `json:"quantity"`
+	PriceMinor int64 `json:"price_minor"`
 }
 
 func (q *Queries) CreateOrderProduct(ctx context.Context, arg CreateOrderProductParams) (OrderProduct, error) {
@@ -55,7 +95,7 @@ func (q *Queries) CreateOrderProduct(ctx context.Context, arg CreateOrderProduct
 		arg.OrderID,
 		arg.ProductID,
 		arg.Quantity,
-		arg.Price,
+		arg.PriceMinor,
 	)
 	var i OrderProduct
 	err := row.Scan(
@@ -63,7 +103,7 @@ func (q *Queries) CreateOrderProduct(ctx context.Context, arg CreateOrderProduct
 		&i.OrderID,
 		&i.ProductID,
 		&i.Quantity,
-		&i.Price,
+		&i.PriceMinor,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -71,7 +111,7 @@ func (q *Queries) CreateOrderProduct(ctx context.Context, arg CreateOrderProduct
 }
 
 const getOrderByID = `-- name: GetOrderByID :one
-SELECT id, user_id, status, total_amount, created_at, updated_at FROM orders
+SELECT id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor FROM orders
 WHERE id = $1 LIMIT 1
 `
 
@@ -82,15 +122,41 @@ func (q *Queries) GetOrderByID(ctx context.Context, id int32) (Order, error) {
 		&i.ID,
 		&i.UserID,
 		&i.Status,
-		&i.TotalAmount,
+		&i.TotalAmountMinor,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.PaidAmountMinor,
+	)
+	return i, err
+}
+
+const getOrderByIDForUpdate = `-- name: GetOrderByIDForUpdate :one
+SELECT id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor FROM orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderByIDForUpdate(ctx context.Context, id int32) (Order, error) {
+	row := q.db.QueryRow(ctx, getOrderByIDForUpdate, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalAmountMinor,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.PaidAmountMinor,
 	)
 	return i, err
 }
 
 const getOrderProductsByOrderID = `-- name: GetOrderProductsByOrderID :many
-SELECT id, order_id, product_id, quantity, price, created_at, updated_at FROM order_products
+SELECT id, order_id, product_id, quantity, price_minor, created_at, updated_at FROM order_products
 WHERE order_id = $1
 `
 
@@ -108,7 +174,41 @@ func (q *Queries) GetOrderProductsByOrderID(ctx context.Context, orderID int32)
 			&i.OrderID,
 			&i.ProductID,
 			&i.Quantity,
-			&i.Price,
+			&i.PriceMinor,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderProductsByOrderIDs = `-- name: GetOrderProductsByOrderIDs :many
+SELECT id, order_id, product_id, quantity, price_minor, created_at, updated_at FROM order_products
+WHERE order_id = ANY($1::INTEGER[])
+ORDER BY order_id, id
+`
+
+func (q *Queries) GetOrderProductsByOrderIDs(ctx context.Context, orderIds []int32) ([]OrderProduct, error) {
+	rows, err := q.db.Query(ctx, getOrderProductsByOrderIDs, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrderProduct{}
+	for rows.Next() {
+		var i OrderProduct
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.PriceMinor,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -123,32 +223,47 @@ func (q *Queries) GetOrderProductsByOrderID(ctx context.Context, orderID int32)
 }
 
 const getOrderWithProducts = `-- name: GetOrderWithProducts :one
-SELECT 
+SELECT
     o.id,
     o.user_id,
     o.status,
+    o.total_amount_minor,
+    o.currency,
+    o.version,
+    o.paid_amount_minor,
     o.created_at,
     o.updated_at,
-    json_agg(
-        json_build_object(
-            'id', op.id,
-            'product_id', op.product_id,
-            'quantity', op.quantity
-        )
-    ) FILTER (WHERE op.id IS NOT NULL) as products
+    COALESCE(
+        json_agg(
+            json_build_object(
+                'id', op.id,
+                'order_id', op.order_id,
+                'product_id', op.product_id,
+                'quantity', op.quantity,
+                'price_minor', op.price_minor,
+                'created_at', op.created_at,
+                'updated_at', op.updated_at
+            ) ORDER BY op.id
+        ) FILTER (WHERE op.id IS NOT NULL),
+        '[]'
+    )::JSON AS products
 FROM orders o
 LEFT JOIN order_products op ON o.id = op.order_id
 WHERE o.id = $1
-GROUP BY o.id, o.user_id, o.status, o.created_at, o.updated_at
+GROUP BY o.id
 `
 
 type GetOrderWithProductsRow struct {
-	ID        int32            `json:"id"`
-	UserID    int32            `json:"user_id"`
-	Status    string           `json:"status"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
-	Products  []byte           `json:"products"`
+	ID               int32            `json:"id"`
+	UserID           int32            `json:"user_id"`
+	Status           string           `json:"status"`
+	TotalAmountMinor int64            `json:"total_amount_minor"`
+	Currency         string           `json:"currency"`
+	Version          int32            `json:"version"`
+	PaidAmountMinor  int64            `json:"paid_amount_minor"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+	Products         []byte           `json:"products"`
 }
 
 func (q *Queries) GetOrderWithProducts(ctx context.Context, id int32) (GetOrderWithProductsRow, error) {
@@ -158,6 +273,10 @@ func (q *Queries) GetOrderWithProducts(ctx context.Context, id int32) (GetOrderW
 		&i.ID,
 		&i.UserID,
 		&i.Status,
+		&i.TotalAmountMinor,
+		&i.Currency,
+		&i.Version,
+		&i.PaidAmountMinor,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Products,
@@ -166,9 +285,9 @@ func (q *Queries) GetOrderWithProducts(ctx context.Context, id int32) (GetOrderW
 }
 
 const getOrdersByUserID = `-- name: GetOrdersByUserID :many
-SELECT id, user_id, status, total_amount, created_at, updated_at FROM orders
+SELECT id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor FROM orders
 WHERE user_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $2 OFFSET $3
 `
 
@@ -191,9 +310,64 @@ func (q *Queries) GetOrdersByUserID(ctx context.Context, arg GetOrdersByUserIDPa
 			&i.ID,
 			&i.UserID,
 			&i.Status,
-			&i.TotalAmount,
+			&i.TotalAmountMinor,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
+			&i.Version,
+			&i.PaidAmountMinor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrdersByUserIDAfter = `-- name: GetOrdersByUserIDAfter :many
+SELECT id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor FROM orders
+WHERE user_id = $1
+  AND (created_at, id) < ($2::TIMESTAMP, $3::INTEGER)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetOrdersByUserIDAfterParams struct {
+	UserID          int32            `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        int32            `json:"cursor_id"`
+	Limit           int32            `json:"limit"`
+}
+
+// Orders after the cursor position in (created_at, id) DESC order. The first page is
+// read with GetOrdersByUserID.
+func (q *Queries) GetOrdersByUserIDAfter(ctx context.Context, arg GetOrdersByUserIDAfterParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrdersByUserIDAfter,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Status,
+			&i.TotalAmountMinor,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.Version,
+			&i.PaidAmountMinor,
 		); err != nil {
 			return nil, err
 		}
@@ -217,28 +391,50 @@ func (q *Queries) GetOrdersByUserIDCount(ctx context.Context, userID int32) (int
 	return count, err
 }
 
-const updateOrderStatus = `-- name: UpdateOrderStatus :one
+const recordOrderPayment = `-- name: RecordOrderPayment :exec
 UPDATE orders
-SET status = $2
+SET paid_amount_minor = $2
 WHERE id = $1
-RETURNING id, user_id, status, total_amount, created_at, updated_at
+`
+
+type RecordOrderPaymentParams struct {
+	ID              int32 `json:"id"`
+	PaidAmountMinor int64 `json:"paid_amount_minor"`
+}
+
+func (q *Queries) RecordOrderPayment(ctx context.Context, arg RecordOrderPaymentParams) error {
+	_, err := q.db.Exec(ctx, recordOrderPayment, arg.ID, arg.PaidAmountMinor)
+	return err
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :one
+UPDATE orders
+SET status = $2,
+    version = version + 1
+WHERE id = $1 AND version = $3
+RETURNING id, user_id, status, total_amount_minor, created_at, updated_at, currency, version, paid_amount_minor
 `
 
 type UpdateOrderStatusParams struct {
-	ID     int32  `json:"id"`
-	Status string `json:"status"`
+	ID      int32  `json:"id"`
+	Status  string `json:"status"`
+	Version int32  `json:"version"`
 }
 
+// Returns no rows when the order is not at the expected version.
 func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error) {
-	row := q.db.QueryRow(ctx, updateOrderStatus, arg.ID, arg.Status)
+	row := q.db.QueryRow(ctx, updateOrderStatus, arg.ID, arg.Status, arg.Version)
 	var i Order
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.Status,
-		&i.TotalAmount,
+		&i.TotalAmountMinor,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.PaidAmountMinor,
 	)
 	return i, err
 }
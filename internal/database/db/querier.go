@@ -6,17 +6,57 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// Records a change that keeps the status. Returns no rows when the order is not at
+	// the expected version.
+	BumpOrderVersion(ctx context.Context, arg BumpOrderVersionParams) (Order, error)
+	// Leases the oldest due event of each order until locked_until. Leased events are
+	// skipped by other relays, so the claim commits before the events are published.
+	ClaimPendingOutboxEvents(ctx context.Context, arg ClaimPendingOutboxEventsParams) ([]Outbox, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreateOrderCancellation(ctx context.Context, arg CreateOrderCancellationParams) (OrderCancellation, error)
 	CreateOrderProduct(ctx context.Context, arg CreateOrderProductParams) (OrderProduct, error)
+	CreateOrderStatusHistory(ctx context.Context, arg CreateOrderStatusHistoryParams) (OrderStatusHistory, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error)
+	CreateRefundItem(ctx context.Context, arg CreateRefundItemParams) (RefundItem, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteProcessedEventsBefore(ctx context.Context, processedAt pgtype.Timestamp) (int64, error)
+	GetCompletedRefundTotal(ctx context.Context, orderID int32) (int64, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetOrderByID(ctx context.Context, id int32) (Order, error)
+	GetOrderByIDForUpdate(ctx context.Context, id int32) (Order, error)
+	GetOrderCancellationByOrderID(ctx context.Context, orderID int32) (OrderCancellation, error)
 	GetOrderProductsByOrderID(ctx context.Context, orderID int32) ([]OrderProduct, error)
+	GetOrderProductsByOrderIDs(ctx context.Context, orderIds []int32) ([]OrderProduct, error)
+	GetOrderStatusHistory(ctx context.Context, orderID int32) ([]OrderStatusHistory, error)
 	GetOrderWithProducts(ctx context.Context, id int32) (GetOrderWithProductsRow, error)
 	GetOrdersByUserID(ctx context.Context, arg GetOrdersByUserIDParams) ([]Order, error)
+	// Orders after the cursor position in (created_at, id) DESC order. The first page is
+	// read with GetOrdersByUserID.
+	GetOrdersByUserIDAfter(ctx context.Context, arg GetOrdersByUserIDAfterParams) ([]Order, error)
 	GetOrdersByUserIDCount(ctx context.Context, userID int32) (int64, error)
+	GetRefundByIDForUpdate(ctx context.Context, id int32) (Refund, error)
+	GetRefundItemsByOrderID(ctx context.Context, orderID int32) ([]RefundItem, error)
+	GetRefundItemsByRefundID(ctx context.Context, refundID int32) ([]RefundItem, error)
+	// Quantities and amounts already refunded or in flight per order line; failed refunds are excluded.
+	GetRefundedAmountsByOrderID(ctx context.Context, orderID int32) ([]GetRefundedAmountsByOrderIDRow, error)
+	GetRefundsByOrderID(ctx context.Context, orderID int32) ([]Refund, error)
+	// Returns 0 rows affected when the event was already processed.
+	MarkEventProcessed(ctx context.Context, arg MarkEventProcessedParams) (int64, error)
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventSent(ctx context.Context, id int64) error
+	RecordOrderPayment(ctx context.Context, arg RecordOrderPaymentParams) error
+	// Inserts a new key, or takes over an expired one. Returns no rows when a live key exists.
+	ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (IdempotencyKey, error)
+	SetIdempotencyKeyOrder(ctx context.Context, arg SetIdempotencyKeyOrderParams) error
+	// Returns no rows when the order is not at the expected version.
 	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
+	UpdateRefundStatus(ctx context.Context, arg UpdateRefundStatusParams) (Refund, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,9 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_outbox_updated_at ON outbox;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_outbox_pending;
+DROP INDEX IF EXISTS idx_outbox_aggregate_pending;
+
+-- Drop tables
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,22 @@
+-- Outbox table for events published to Kafka by the relay
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id INTEGER NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_outbox_aggregate_pending ON outbox(aggregate_id, id) WHERE status = 'PENDING';
+
+-- Triggers
+CREATE TRIGGER update_outbox_updated_at BEFORE UPDATE ON outbox
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
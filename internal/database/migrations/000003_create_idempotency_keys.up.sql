@@ -0,0 +1,14 @@
+-- Idempotency keys for CreateOrder replays
+CREATE TABLE idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    order_id INTEGER REFERENCES orders(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, idempotency_key)
+);
+
+-- Indexes
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,12 @@
+-- Restore floating point amounts
+ALTER TABLE order_products
+    ALTER COLUMN price_minor TYPE FLOAT
+    USING price_minor / 100.0;
+ALTER TABLE order_products RENAME COLUMN price_minor TO price;
+
+ALTER TABLE orders
+    ALTER COLUMN total_amount_minor TYPE FLOAT
+    USING total_amount_minor / 100.0;
+ALTER TABLE orders RENAME COLUMN total_amount_minor TO total_amount;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,13 @@
+-- Store money as integer minor units (e.g. cents) with an explicit currency.
+-- Existing amounts were recorded in USD, which has two decimal places.
+ALTER TABLE orders ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE orders RENAME COLUMN total_amount TO total_amount_minor;
+ALTER TABLE orders
+    ALTER COLUMN total_amount_minor TYPE BIGINT
+    USING ROUND((total_amount_minor * 100)::NUMERIC)::BIGINT;
+
+ALTER TABLE order_products RENAME COLUMN price TO price_minor;
+ALTER TABLE order_products
+    ALTER COLUMN price_minor TYPE BIGINT
+    USING ROUND((price_minor * 100)::NUMERIC)::BIGINT;
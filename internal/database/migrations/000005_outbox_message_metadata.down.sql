@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_outbox_event_id;
+
+-- Drop columns
+ALTER TABLE outbox DROP COLUMN IF EXISTS headers;
+ALTER TABLE outbox DROP COLUMN IF EXISTS message_key;
+ALTER TABLE outbox DROP COLUMN IF EXISTS event_id;
@@ -0,0 +1,10 @@
+-- Message key, event ID and headers for outbox events
+ALTER TABLE outbox ADD COLUMN event_id UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE outbox ADD COLUMN message_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+
+-- Existing events are keyed by their order
+UPDATE outbox SET message_key = aggregate_id::TEXT;
+
+-- Indexes
+CREATE UNIQUE INDEX idx_outbox_event_id ON outbox(event_id);
@@ -0,0 +1,6 @@
+-- Events already applied by consumers, used to make consumption idempotent
+CREATE TABLE processed_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_order_cancellations_reason_code;
+
+-- Drop tables
+DROP TABLE IF EXISTS order_cancellations;
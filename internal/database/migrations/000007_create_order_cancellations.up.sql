@@ -0,0 +1,13 @@
+-- Cancellation details, one row per cancelled order
+CREATE TABLE order_cancellations (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    previous_status VARCHAR(50) NOT NULL,
+    reason_code VARCHAR(50) NOT NULL,
+    note TEXT,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_order_cancellations_reason_code ON order_cancellations(reason_code);
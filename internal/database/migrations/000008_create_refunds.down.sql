@@ -0,0 +1,11 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_refunds_updated_at ON refunds;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_refund_items_order_product_id;
+DROP INDEX IF EXISTS idx_refund_items_refund_id;
+DROP INDEX IF EXISTS idx_refunds_order_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
@@ -0,0 +1,19 @@
+-- Every status an order has been in, with who moved it there and why
+CREATE TABLE order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id, created_at);
+
+-- Existing orders start their history at their current status
+INSERT INTO order_status_history (order_id, from_status, to_status, actor, source, reason, created_at)
+SELECT id, NULL, status, 'system', 'MIGRATION', 'status before history was recorded', updated_at
+FROM orders;
@@ -0,0 +1,2 @@
+-- Drop version column
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
@@ -0,0 +1,2 @@
+-- Version for optimistic concurrency control, incremented on every mutation
+ALTER TABLE orders ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Drop index
+DROP INDEX CONCURRENTLY IF EXISTS idx_orders_created_at;
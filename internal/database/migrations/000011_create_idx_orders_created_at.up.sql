@@ -0,0 +1,2 @@
+-- SearchOrders sorted by created_at; built concurrently so writes are not blocked
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_orders_created_at ON orders(created_at, id);
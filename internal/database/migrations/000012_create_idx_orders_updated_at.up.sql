@@ -0,0 +1,2 @@
+-- SearchOrders sorted by updated_at
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_orders_updated_at ON orders(updated_at, id);
@@ -0,0 +1,2 @@
+-- SearchOrders sorted or filtered by total amount
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_orders_total_amount_minor ON orders(total_amount_minor, id);
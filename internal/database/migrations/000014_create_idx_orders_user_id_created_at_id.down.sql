@@ -0,0 +1,2 @@
+-- Drop index
+DROP INDEX CONCURRENTLY IF EXISTS idx_orders_user_id_created_at_id;
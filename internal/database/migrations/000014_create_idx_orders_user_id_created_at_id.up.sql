@@ -0,0 +1,2 @@
+-- A user's orders in (created_at, id) order, for listings and keyset pagination
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_orders_user_id_created_at_id ON orders(user_id, created_at DESC, id DESC);
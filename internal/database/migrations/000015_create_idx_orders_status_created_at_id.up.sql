@@ -0,0 +1,2 @@
+-- SearchOrders filtered by status and sorted by created_at
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_orders_status_created_at_id ON orders(status, created_at, id);
@@ -0,0 +1,2 @@
+-- SearchOrders filtered by product
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_order_products_product_id ON order_products(product_id, order_id);
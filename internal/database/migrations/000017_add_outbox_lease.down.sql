@@ -0,0 +1,2 @@
+-- Drop columns
+ALTER TABLE outbox DROP COLUMN IF EXISTS locked_until;
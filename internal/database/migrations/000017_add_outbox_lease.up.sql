@@ -0,0 +1,3 @@
+-- Events claimed by a relay are leased to it until locked_until, so the claim can
+-- commit before the events are published
+ALTER TABLE outbox ADD COLUMN locked_until TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Retention purge of processed events
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_processed_events_processed_at ON processed_events(processed_at);
@@ -0,0 +1,2 @@
+-- Drop columns
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_amount_minor;
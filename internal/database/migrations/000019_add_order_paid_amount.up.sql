@@ -0,0 +1,12 @@
+-- Amount actually captured by the payment service, in minor units. Refunds can never
+-- exceed it; orders cancelled before payment stay at zero.
+ALTER TABLE orders ADD COLUMN paid_amount_minor BIGINT NOT NULL DEFAULT 0;
+
+-- Orders that reached CONFIRMED were paid in full
+UPDATE orders o
+SET paid_amount_minor = o.total_amount_minor
+WHERE o.status IN ('CONFIRMED', 'PROCESSING', 'SHIPPED', 'DELIVERED', 'REFUNDED')
+   OR (o.status = 'CANCELLED' AND EXISTS (
+        SELECT 1 FROM order_status_history h
+        WHERE h.order_id = o.id AND h.to_status = 'CONFIRMED'
+   ));
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"order-service/internal/database/db"
+)
+
+// OrderSortColumn is an orders column SearchOrders can sort by. Rows are ordered
+// by id after it, matching the (column, id) search indexes.
+type OrderSortColumn string
+
+const (
+	SortCreatedAt   OrderSortColumn = "o.created_at"
+	SortUpdatedAt   OrderSortColumn = "o.updated_at"
+	SortTotalAmount OrderSortColumn = "o.total_amount_minor"
+	SortID          OrderSortColumn = "o.id"
+)
+
+// orderColumns selects the columns of db.Order, in field order
+const orderColumns = "o.id, o.user_id, o.status, o.total_amount_minor, o.created_at, o.updated_at, o.currency, o.version, o.paid_amount_minor"
+
+// OrderSearch selects the orders returned by SearchOrders. Filters left at their
+// zero value are not part of the statement at all, rather than passed as NULL, so
+// each combination is planned on its own and can use the matching index.
+type OrderSearch struct {
+	// UserID and ProductID are ignored when zero
+	UserID    int32
+	ProductID int32
+	// Statuses matches any of the given statuses; empty matches all
+	Statuses []string
+	// CreatedFrom is inclusive and CreatedTo exclusive; nil leaves the bound open
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// MinTotalMinor and MaxTotalMinor are inclusive; nil leaves the bound open
+	MinTotalMinor *int64
+	MaxTotalMinor *int64
+
+	SortBy     OrderSortColumn
+	Descending bool
+	// AfterID, when set, continues a keyset listing after the row with this id and
+	// SortBy value AfterValue. AfterValue is unused when sorting by id.
+	AfterValue interface{}
+	AfterID    int32
+
+	Limit  int32
+	Offset int32
+}
+
+// statement accumulates the conditions and positional arguments of a query
+type statement struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg adds a positional argument and returns its placeholder
+func (s *statement) arg(value interface{}) string {
+	s.args = append(s.args, value)
+	return "$" + strconv.Itoa(len(s.args))
+}
+
+func (s *statement) where(condition string) {
+	s.conditions = append(s.conditions, condition)
+}
+
+func (s *statement) whereClause() string {
+	if len(s.conditions) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(s.conditions, "\n  AND ")
+}
+
+// filter adds the conditions shared by SearchOrders and CountOrders
+func (search OrderSearch) filter(s *statement) {
+	if search.UserID > 0 {
+		s.where("o.user_id = " + s.arg(search.UserID))
+	}
+	if len(search.Statuses) > 0 {
+		s.where("o.status = ANY(" + s.arg(search.Statuses) + ")")
+	}
+	if search.CreatedFrom != nil {
+		s.where("o.created_at >= " + s.arg(search.CreatedFrom.UTC()))
+	}
+	if search.CreatedTo != nil {
+		s.where("o.created_at < " + s.arg(search.CreatedTo.UTC()))
+	}
+	if search.MinTotalMinor != nil {
+		s.where("o.total_amount_minor >= " + s.arg(*search.MinTotalMinor))
+	}
+	if search.MaxTotalMinor != nil {
+		s.where("o.total_amount_minor <= " + s.arg(*search.MaxTotalMinor))
+	}
+	if search.ProductID > 0 {
+		s.where("EXISTS (SELECT 1 FROM order_products op WHERE op.order_id = o.id AND op.product_id = " + s.arg(search.ProductID) + ")")
+	}
+}
+
+// searchQuery builds the SearchOrders statement. The ORDER BY clause is only ever
+// built from the OrderSortColumn constants.
+func (search OrderSearch) searchQuery() (string, []interface{}, error) {
+	switch search.SortBy {
+	case SortCreatedAt, SortUpdatedAt, SortTotalAmount, SortID:
+	default:
+		return "", nil, fmt.Errorf("unsupported sort column %q", search.SortBy)
+	}
+
+	direction, comparison := "ASC", ">"
+	if search.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	var s statement
+	search.filter(&s)
+
+	if search.AfterID > 0 {
+		if search.SortBy == SortID {
+			s.where("o.id " + comparison + " " + s.arg(search.AfterID))
+		} else {
+			s.where(fmt.Sprintf("(%s, o.id) %s (%s, %s)", search.SortBy, comparison, s.arg(search.AfterValue), s.arg(search.AfterID)))
+		}
+	}
+
+	orderBy := "o.id " + direction
+	if search.SortBy != SortID {
+		orderBy = string(search.SortBy) + " " + direction + ", " + orderBy
+	}
+
+	sql := "-- name: SearchOrders :many\nSELECT " + orderColumns + " FROM orders o" + s.whereClause() +
+		"\nORDER BY " + orderBy +
+		"\nLIMIT " + s.arg(search.Limit)
+	if search.Offset > 0 {
+		sql += " OFFSET " + s.arg(search.Offset)
+	}
+
+	return sql, s.args, nil
+}
+
+// countQuery builds the CountOrders statement, ignoring sorting and paging
+func (search OrderSearch) countQuery() (string, []interface{}) {
+	var s statement
+	search.filter(&s)
+	return "-- name: CountOrders :one\nSELECT COUNT(*) FROM orders o" + s.whereClause(), s.args
+}
+
+// SearchOrders returns the orders matching search, in its sort order
+func SearchOrders(ctx context.Context, conn db.DBTX, search OrderSearch) ([]db.Order, error) {
+	sql, args, err := search.searchQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[db.Order])
+}
+
+// CountOrders returns the number of orders matching the filters of search
+func CountOrders(ctx context.Context, conn db.DBTX, search OrderSearch) (int64, error) {
+	sql, args := search.countQuery()
+
+	var count int64
+	err := conn.QueryRow(ctx, sql, args...).Scan(&count)
+	return count, err
+}
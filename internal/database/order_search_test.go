@@ -0,0 +1,99 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderSearchQuery(t *testing.T) {
+	from := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	minTotal := int64(1000)
+
+	tests := []struct {
+		name     string
+		search   OrderSearch
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:   "no filters",
+			search: OrderSearch{SortBy: SortCreatedAt, Descending: true, Limit: 10},
+			wantSQL: "-- name: SearchOrders :many\nSELECT " + orderColumns + " FROM orders o" +
+				"\nORDER BY o.created_at DESC, o.id DESC\nLIMIT $1",
+			wantArgs: []interface{}{int32(10)},
+		},
+		{
+			name: "filters with offset",
+			search: OrderSearch{
+				UserID:        7,
+				Statuses:      []string{"PENDING", "CONFIRMED"},
+				CreatedFrom:   &from,
+				MinTotalMinor: &minTotal,
+				ProductID:     3,
+				SortBy:        SortUpdatedAt,
+				Limit:         20,
+				Offset:        40,
+			},
+			wantSQL: "-- name: SearchOrders :many\nSELECT " + orderColumns + " FROM orders o" +
+				"\nWHERE o.user_id = $1" +
+				"\n  AND o.status = ANY($2)" +
+				"\n  AND o.created_at >= $3" +
+				"\n  AND o.total_amount_minor >= $4" +
+				"\n  AND EXISTS (SELECT 1 FROM order_products op WHERE op.order_id = o.id AND op.product_id = $5)" +
+				"\nORDER BY o.updated_at ASC, o.id ASC\nLIMIT $6 OFFSET $7",
+			wantArgs: []interface{}{int32(7), []string{"PENDING", "CONFIRMED"}, from, int64(1000), int32(3), int32(20), int32(40)},
+		},
+		{
+			name:   "keyset descending",
+			search: OrderSearch{SortBy: SortCreatedAt, Descending: true, AfterValue: from, AfterID: 42, Limit: 11},
+			wantSQL: "-- name: SearchOrders :many\nSELECT " + orderColumns + " FROM orders o" +
+				"\nWHERE (o.created_at, o.id) < ($1, $2)" +
+				"\nORDER BY o.created_at DESC, o.id DESC\nLIMIT $3",
+			wantArgs: []interface{}{from, int32(42), int32(11)},
+		},
+		{
+			name:   "keyset by id",
+			search: OrderSearch{SortBy: SortID, AfterID: 42, Limit: 11},
+			wantSQL: "-- name: SearchOrders :many\nSELECT " + orderColumns + " FROM orders o" +
+				"\nWHERE o.id > $1" +
+				"\nORDER BY o.id ASC\nLIMIT $2",
+			wantArgs: []interface{}{int32(42), int32(11)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.search.searchQuery()
+			if err != nil {
+				t.Fatalf("searchQuery() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("searchQuery() sql =\n%s\nwant\n%s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("searchQuery() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOrderSearchQueryRejectsUnknownSort(t *testing.T) {
+	for _, sortBy := range []OrderSortColumn{"", "o.status", "o.created_at; DROP TABLE orders"} {
+		if _, _, err := (OrderSearch{SortBy: sortBy}).searchQuery(); err == nil {
+			t.Errorf("searchQuery() with sort %q: expected an error", sortBy)
+		}
+	}
+}
+
+func TestOrderCountQuery(t *testing.T) {
+	sql, args := OrderSearch{UserID: 7, SortBy: SortCreatedAt, AfterID: 42, Limit: 10, Offset: 20}.countQuery()
+
+	wantSQL := "-- name: CountOrders :one\nSELECT COUNT(*) FROM orders o\nWHERE o.user_id = $1"
+	if sql != wantSQL {
+		t.Errorf("countQuery() sql = %q, want %q", sql, wantSQL)
+	}
+	if !reflect.DeepEqual(args, []interface{}{int32(7)}) {
+		t.Errorf("countQuery() args = %v, want [7]", args)
+	}
+}
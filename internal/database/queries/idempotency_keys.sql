@@ -0,0 +1,24 @@
+-- name: ReserveIdempotencyKey :one
+-- Inserts a new key, or takes over an expired one. Returns no rows when a live key exists.
+INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    order_id = NULL,
+    expires_at = EXCLUDED.expires_at,
+    created_at = CURRENT_TIMESTAMP
+WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: SetIdempotencyKeyOrder :exec
+UPDATE idempotency_keys
+SET order_id = $2
+WHERE id = $1;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= CURRENT_TIMESTAMP;
@@ -0,0 +1,8 @@
+-- name: CreateOrderCancellation :one
+INSERT INTO order_cancellations (order_id, previous_status, reason_code, note, actor)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetOrderCancellationByOrderID :one
+SELECT * FROM order_cancellations
+WHERE order_id = $1 LIMIT 1;
@@ -1,30 +1,59 @@
 -- name: CreateOrder :one
-INSERT INTO orders (user_id, status, total_amount)
-VALUES ($1, $2, $3)
+INSERT INTO orders (user_id, status, total_amount_minor, currency)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetOrderByID :one
 SELECT * FROM orders
 WHERE id = $1 LIMIT 1;
 
+-- name: GetOrderByIDForUpdate :one
+SELECT * FROM orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
 -- name: GetOrdersByUserID :many
 SELECT * FROM orders
 WHERE user_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $2 OFFSET $3;
 
+-- name: GetOrdersByUserIDAfter :many
+-- Orders after the cursor position in (created_at, id) DESC order. The first page is
+-- read with GetOrdersByUserID.
+SELECT * FROM orders
+WHERE user_id = sqlc.arg('user_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::TIMESTAMP, sqlc.arg('cursor_id')::INTEGER)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: GetOrdersByUserIDCount :one
 SELECT COUNT(*) FROM orders
 WHERE user_id = $1;
 
+-- name: RecordOrderPayment :exec
+UPDATE orders
+SET paid_amount_minor = $2
+WHERE id = $1;
+
+-- name: BumpOrderVersion :one
+-- Records a change that keeps the status. Returns no rows when the order is not at
+-- the expected version.
+UPDATE orders
+SET version = version + 1
+WHERE id = $1 AND version = $2
+RETURNING *;
+
 -- name: UpdateOrderStatus :one
+-- Returns no rows when the order is not at the expected version.
 UPDATE orders
-SET status = $2
-WHERE id = $1
+SET status = $2,
+    version = version + 1
+WHERE id = $1 AND version = $3
 RETURNING *;
 
 -- name: CreateOrderProduct :one
-INSERT INTO order_products (order_id, product_id, quantity, price)
+INSERT INTO order_products (order_id, product_id, quantity, price_minor)
 VALUES ($1, $2, $3, $4)
 RETURNING *;
 
@@ -32,21 +61,37 @@ RETURNING *;
 SELECT * FROM order_products
 WHERE order_id = $1;
 
+-- name: GetOrderProductsByOrderIDs :many
+SELECT * FROM order_products
+WHERE order_id = ANY(sqlc.arg('order_ids')::INTEGER[])
+ORDER BY order_id, id;
+
 -- name: GetOrderWithProducts :one
-SELECT 
+SELECT
     o.id,
     o.user_id,
     o.status,
+    o.total_amount_minor,
+    o.currency,
+    o.version,
+    o.paid_amount_minor,
     o.created_at,
     o.updated_at,
-    json_agg(
-        json_build_object(
-            'id', op.id,
-            'product_id', op.product_id,
-            'quantity', op.quantity
-        )
-    ) FILTER (WHERE op.id IS NOT NULL) as products
+    COALESCE(
+        json_agg(
+            json_build_object(
+                'id', op.id,
+                'order_id', op.order_id,
+                'product_id', op.product_id,
+                'quantity', op.quantity,
+                'price_minor', op.price_minor,
+                'created_at', op.created_at,
+                'updated_at', op.updated_at
+            ) ORDER BY op.id
+        ) FILTER (WHERE op.id IS NOT NULL),
+        '[]'
+    )::JSON AS products
 FROM orders o
 LEFT JOIN order_products op ON o.id = op.order_id
 WHERE o.id = $1
-GROUP BY o.id, o.user_id, o.status, o.created_at, o.updated_at;
\ No newline at end of file
+GROUP BY o.id;
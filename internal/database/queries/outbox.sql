@@ -0,0 +1,44 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (aggregate_id, topic, message_key, headers, payload)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ClaimPendingOutboxEvents :many
+-- Leases the oldest due event of each order until locked_until. Leased events are
+-- skipped by other relays, so the claim commits before the events are published.
+UPDATE outbox
+SET locked_until = sqlc.arg('locked_until')
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.status = 'PENDING'
+      AND o.next_attempt_at <= CURRENT_TIMESTAMP
+      AND (o.locked_until IS NULL OR o.locked_until <= CURRENT_TIMESTAMP)
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox prev
+          WHERE prev.aggregate_id = o.aggregate_id
+            AND prev.status = 'PENDING'
+            AND prev.id < o.id
+      )
+    ORDER BY o.id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxEventSent :exec
+UPDATE outbox
+SET status = 'SENT',
+    attempts = attempts + 1,
+    last_error = NULL,
+    locked_until = NULL,
+    sent_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET status = $2,
+    attempts = attempts + 1,
+    last_error = $3,
+    next_attempt_at = $4,
+    locked_until = NULL
+WHERE id = $1;
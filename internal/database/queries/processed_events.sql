@@ -0,0 +1,9 @@
+-- name: MarkEventProcessed :execrows
+-- Returns 0 rows affected when the event was already processed.
+INSERT INTO processed_events (event_id, topic)
+VALUES ($1, $2)
+ON CONFLICT (event_id) DO NOTHING;
+
+-- name: DeleteProcessedEventsBefore :execrows
+DELETE FROM processed_events
+WHERE processed_at < $1;
@@ -0,0 +1,53 @@
+-- name: CreateRefund :one
+INSERT INTO refunds (order_id, amount_minor, currency, reason, actor)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: CreateRefundItem :one
+INSERT INTO refund_items (refund_id, order_product_id, quantity, amount_minor)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetRefundByIDForUpdate :one
+SELECT * FROM refunds
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateRefundStatus :one
+UPDATE refunds
+SET status = $2,
+    failure_reason = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: GetRefundsByOrderID :many
+SELECT * FROM refunds
+WHERE order_id = $1
+ORDER BY created_at, id;
+
+-- name: GetRefundItemsByOrderID :many
+SELECT refund_items.* FROM refund_items
+JOIN refunds ON refunds.id = refund_items.refund_id
+WHERE refunds.order_id = $1
+ORDER BY refund_items.id;
+
+-- name: GetRefundItemsByRefundID :many
+SELECT * FROM refund_items
+WHERE refund_id = $1
+ORDER BY id;
+
+-- name: GetRefundedAmountsByOrderID :many
+-- Quantities and amounts already refunded or in flight per order line; failed refunds are excluded.
+SELECT
+    refund_items.order_product_id,
+    COALESCE(SUM(refund_items.quantity), 0)::BIGINT AS refunded_quantity,
+    COALESCE(SUM(refund_items.amount_minor), 0)::BIGINT AS refunded_amount_minor
+FROM refund_items
+JOIN refunds ON refunds.id = refund_items.refund_id
+WHERE refunds.order_id = $1 AND refunds.status <> 'FAILED'
+GROUP BY refund_items.order_product_id;
+
+-- name: GetCompletedRefundTotal :one
+SELECT COALESCE(SUM(amount_minor), 0)::BIGINT AS total
+FROM refunds
+WHERE order_id = $1 AND status = 'COMPLETED';
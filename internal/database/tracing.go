@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-service/internal/tracing"
+)
+
+// queryTracer wraps every query in a span named after its sqlc query name
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := queryName(data.SQL)
+	ctx, _ = tracing.Tracer().Start(ctx, "db."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+
+	err := data.Err
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		// Not found is an expected outcome, not a failure
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+}
+
+// queryName returns the name from a sqlc "-- name: GetOrderByID :one" header, or the
+// first keyword of the statement for other queries such as BEGIN and COMMIT
+func queryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		if name, _, found := strings.Cut(rest, " "); found {
+			return name
+		}
+	}
+	if keyword, _, _ := strings.Cut(sql, " "); keyword != "" {
+		return strings.ToLower(keyword)
+	}
+	return "query"
+}
+
+// tracedTx wraps a transaction in a span. Statements run through it become children
+// of the transaction span, and the span ends on commit or rollback.
+type tracedTx struct {
+	pgx.Tx
+	span trace.Span
+}
+
+func (t *tracedTx) spanContext(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, t.span)
+}
+
+func (t *tracedTx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return t.Tx.Exec(t.spanContext(ctx), sql, arguments...)
+}
+
+func (t *tracedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.Tx.Query(t.spanContext(ctx), sql, args...)
+}
+
+func (t *tracedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.Tx.QueryRow(t.spanContext(ctx), sql, args...)
+}
+
+func (t *tracedTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return t.Tx.SendBatch(t.spanContext(ctx), b)
+}
+
+func (t *tracedTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return t.Tx.CopyFrom(t.spanContext(ctx), tableName, columnNames, rowSrc)
+}
+
+func (t *tracedTx) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(t.spanContext(ctx))
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", "commit"))
+	tracing.EndSpan(t.span, err)
+	return err
+}
+
+func (t *tracedTx) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(t.spanContext(ctx))
+	if stderrors.Is(err, pgx.ErrTxClosed) {
+		// The deferred rollback after a commit; the span has already ended
+		return err
+	}
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", "rollback"))
+	tracing.EndSpan(t.span, err)
+	return err
+}
@@ -1,5 +1,7 @@
 package errors
 
+import stderrors "errors"
+
 // Error codes for the order service
 const (
 	CodeUnknown           string = "ORD_UNKNOWN"
@@ -12,21 +14,50 @@ const (
 	CodeInsufficientStock string = "ORD_INSUFFICIENT_STOCK"
 	CodePaymentFailed     string = "ORD_PAYMENT_FAILED"
 	CodeUnauthorized      string = "ORD_UNAUTHORIZED"
+	CodeForbidden         string = "ORD_FORBIDDEN"
 	CodeInternalError     string = "ORD_INTERNAL_ERROR"
 	CodeDatabaseError     string = "ORD_DATABASE_ERROR"
 	CodeKafkaError        string = "ORD_KAFKA_ERROR"
+	CodeIdempotencyReused string = "ORD_IDEMPOTENCY_KEY_REUSED"
+	CodeRefundNotFound    string = "ORD_REFUND_NOT_FOUND"
+	CodeRefundExceedsPaid string = "ORD_REFUND_EXCEEDS_PAID"
+	CodeVersionConflict   string = "ORD_VERSION_CONFLICT"
 )
 
+// FieldViolation describes a single invalid request field
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
 // OrderError represents a custom error with an error code
 type OrderError struct {
-	ErrorCode string
-	Message   string
+	ErrorCode  string
+	Message    string
+	Violations []FieldViolation
+	// cause is the underlying error, if any, reachable through errors.Unwrap
+	cause error
 }
 
 func (e *OrderError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error so errors.Is/As can inspect it
+func (e *OrderError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an OrderError with the same error code, so
+// errors.Is(err, ErrOrderNotFound) matches any not-found error
+func (e *OrderError) Is(target error) bool {
+	t, ok := target.(*OrderError)
+	if !ok {
+		return false
+	}
+	return e.ErrorCode == t.ErrorCode
+}
+
 // Predefined errors
 var (
 	ErrOrderNotFound     = &OrderError{ErrorCode: CodeOrderNotFound, Message: "order not found"}
@@ -38,11 +69,22 @@ var (
 	ErrInsufficientStock = &OrderError{ErrorCode: CodeInsufficientStock, Message: "insufficient stock"}
 	ErrPaymentFailed     = &OrderError{ErrorCode: CodePaymentFailed, Message: "payment failed"}
 	ErrUnauthorized      = &OrderError{ErrorCode: CodeUnauthorized, Message: "unauthorized"}
+	ErrForbidden         = &OrderError{ErrorCode: CodeForbidden, Message: "permission denied"}
 	ErrInternalError     = &OrderError{ErrorCode: CodeInternalError, Message: "internal server error"}
 	ErrDatabaseError     = &OrderError{ErrorCode: CodeDatabaseError, Message: "database error"}
 	ErrKafkaError        = &OrderError{ErrorCode: CodeKafkaError, Message: "kafka error"}
+	ErrIdempotencyReused = &OrderError{ErrorCode: CodeIdempotencyReused, Message: "idempotency key was already used with a different request"}
+	ErrRefundNotFound    = &OrderError{ErrorCode: CodeRefundNotFound, Message: "refund not found"}
+	ErrVersionConflict   = &OrderError{ErrorCode: CodeVersionConflict, Message: "order was modified concurrently"}
 )
 
+var predefined = []*OrderError{
+	ErrOrderNotFound, ErrOrderCreateFailed, ErrOrderUpdateFailed, ErrInvalidInput, ErrInvalidStatus,
+	ErrInvalidProduct, ErrInsufficientStock, ErrPaymentFailed, ErrUnauthorized, ErrForbidden,
+	ErrInternalError, ErrDatabaseError, ErrKafkaError, ErrIdempotencyReused, ErrRefundNotFound,
+	ErrVersionConflict,
+}
+
 // NewOrderError creates a new OrderError with a custom message
 func NewOrderError(code string, message string) *OrderError {
 	return &OrderError{
@@ -51,15 +93,25 @@ func NewOrderError(code string, message string) *OrderError {
 	}
 }
 
-// IsOrderError checks if an error is an OrderError
+// NewFieldError creates a new OrderError for an invalid request field
+func NewFieldError(code string, field string, message string) *OrderError {
+	return &OrderError{
+		ErrorCode:  code,
+		Message:    message,
+		Violations: []FieldViolation{{Field: field, Description: message}},
+	}
+}
+
+// IsOrderError checks if an error is, or wraps, an OrderError
 func IsOrderError(err error) bool {
-	_, ok := err.(*OrderError)
-	return ok
+	var orderErr *OrderError
+	return stderrors.As(err, &orderErr)
 }
 
 // GetErrorCode extracts the error code from an error
 func GetErrorCode(err error) string {
-	if orderErr, ok := err.(*OrderError); ok {
+	var orderErr *OrderError
+	if stderrors.As(err, &orderErr) {
 		return orderErr.ErrorCode
 	}
 	return CodeUnknown
@@ -74,7 +126,8 @@ func ParseError(err error) (string, string) {
 		return CodeUnknown, DefaultErrorMessage
 	}
 
-	if orderErr, ok := err.(*OrderError); ok {
+	var orderErr *OrderError
+	if stderrors.As(err, &orderErr) {
 		return orderErr.ErrorCode, orderErr.Message
 	}
 
@@ -87,7 +140,8 @@ func GetError(err error) *OrderError {
 		return nil
 	}
 
-	if orderErr, ok := err.(*OrderError); ok {
+	var orderErr *OrderError
+	if stderrors.As(err, &orderErr) {
 		return orderErr
 	}
 
@@ -97,13 +151,26 @@ func GetError(err error) *OrderError {
 	}
 }
 
-// Wrap wraps an error with a custom OrderError
+// Wrap wraps an error with an OrderError carrying the fixed message of code, such
+// as "database error", so driver and SQL details never reach the client. err stays
+// reachable through Unwrap for logging.
 func Wrap(code string, err error) *OrderError {
 	if err == nil {
 		return nil
 	}
 	return &OrderError{
 		ErrorCode: code,
-		Message:   err.Error(),
+		Message:   defaultMessage(code),
+		cause:     err,
+	}
+}
+
+// defaultMessage returns the message of the predefined error with code
+func defaultMessage(code string) string {
+	for _, err := range predefined {
+		if err.ErrorCode == code {
+			return err.Message
+		}
 	}
+	return DefaultErrorMessage
 }
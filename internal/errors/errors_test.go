@@ -0,0 +1,40 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	cause := stderrors.New(`pq: duplicate key value violates unique constraint "orders_pkey"`)
+
+	tests := []struct {
+		name        string
+		code        string
+		wantMessage string
+	}{
+		{name: "database", code: CodeDatabaseError, wantMessage: "database error"},
+		{name: "internal", code: CodeInternalError, wantMessage: "internal server error"},
+		{name: "no predefined error", code: "ORD_SOMETHING_ELSE", wantMessage: DefaultErrorMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Wrap(tt.code, cause)
+
+			if err.ErrorCode != tt.code {
+				t.Errorf("Wrap().ErrorCode = %q, want %q", err.ErrorCode, tt.code)
+			}
+			if err.Message != tt.wantMessage || err.Error() != tt.wantMessage {
+				t.Errorf("Wrap() message = %q, want %q", err.Message, tt.wantMessage)
+			}
+			if !stderrors.Is(err, cause) || stderrors.Unwrap(err) != cause {
+				t.Errorf("Wrap() does not unwrap to its cause")
+			}
+		})
+	}
+
+	if Wrap(CodeDatabaseError, nil) != nil {
+		t.Error("Wrap(nil) should return nil")
+	}
+}
@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	stderrors "errors"
+	"log"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"order-service/internal/errors"
+)
+
+// errorDomain identifies this service in google.rpc.ErrorInfo details
+const errorDomain = "order-service"
+
+// grpcCodes maps OrderError codes to canonical gRPC status codes.
+// Codes not listed here map to Internal.
+var grpcCodes = map[string]codes.Code{
+	errors.CodeOrderNotFound:     codes.NotFound,
+	errors.CodeRefundNotFound:    codes.NotFound,
+	errors.CodeInvalidInput:      codes.InvalidArgument,
+	errors.CodeInvalidProduct:    codes.InvalidArgument,
+	errors.CodeInvalidStatus:     codes.FailedPrecondition,
+	errors.CodeInsufficientStock: codes.FailedPrecondition,
+	errors.CodePaymentFailed:     codes.FailedPrecondition,
+	errors.CodeIdempotencyReused: codes.FailedPrecondition,
+	errors.CodeRefundExceedsPaid: codes.FailedPrecondition,
+	errors.CodeVersionConflict:   codes.Aborted,
+	errors.CodeUnauthorized:      codes.Unauthenticated,
+	errors.CodeForbidden:         codes.PermissionDenied,
+}
+
+// grpcCode returns the canonical gRPC status code for an OrderError code
+func grpcCode(errorCode string) codes.Code {
+	if code, ok := grpcCodes[errorCode]; ok {
+		return code
+	}
+	return codes.Internal
+}
+
+// toStatusError converts an error to a gRPC status error carrying the ORD_* code in a
+// google.rpc.ErrorInfo detail and any field violations in a google.rpc.BadRequest detail
+func toStatusError(err error) error {
+	orderErr := errors.GetError(err)
+	if orderErr == nil {
+		return nil
+	}
+	// The client only sees the fixed message; keep the underlying error in the logs
+	if cause := stderrors.Unwrap(orderErr); cause != nil {
+		log.Printf("❌ %s: %v", orderErr.Message, cause)
+	} else if !errors.IsOrderError(err) {
+		log.Printf("❌ %s: %v", orderErr.Message, err)
+	}
+
+	st := status.New(grpcCode(orderErr.ErrorCode), orderErr.Message)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: orderErr.ErrorCode,
+			Domain: errorDomain,
+		},
+	}
+
+	if len(orderErr.Violations) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(orderErr.Violations))
+		for i, v := range orderErr.Violations {
+			violations[i] = &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			}
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// legacyEnvelope is implemented by every order service response
+type legacyEnvelope interface {
+	GetSuccess() bool
+	GetCode() string
+}
+
+// legacyErrorResponse builds the Success=false response of the method for a status
+// error made by toStatusError. It returns nil when err carries no ORD_* code or the
+// method's response has no success, message and code fields.
+func legacyErrorResponse(fullMethod string, err error) proto.Message {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	errorCode := ""
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			errorCode = info.Reason
+		}
+	}
+	if errorCode == "" {
+		return nil
+	}
+
+	// "/package.Service/Method" is described as package.Service.Method
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", "."))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil
+	}
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil
+	}
+	responseType, err := protoregistry.GlobalTypes.FindMessageByName(method.Output().FullName())
+	if err != nil {
+		return nil
+	}
+
+	response := responseType.New()
+	fields := response.Descriptor().Fields()
+	success, message, code := fields.ByName("success"), fields.ByName("message"), fields.ByName("code")
+	if success == nil || message == nil || code == nil {
+		return nil
+	}
+	response.Set(success, protoreflect.ValueOfBool(false))
+	response.Set(message, protoreflect.ValueOfString(st.Message()))
+	response.Set(code, protoreflect.ValueOfString(errorCode))
+
+	return response.Interface()
+}
@@ -0,0 +1,141 @@
+package grpc
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"order-service/internal/errors"
+	"order-service/internal/metrics"
+)
+
+func TestToStatusError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantCode       codes.Code
+		wantReason     string
+		wantMessage    string
+		wantViolations []string
+	}{
+		{
+			name:        "not found",
+			err:         errors.NewOrderError(errors.CodeOrderNotFound, "order not found"),
+			wantCode:    codes.NotFound,
+			wantReason:  errors.CodeOrderNotFound,
+			wantMessage: "order not found",
+		},
+		{
+			name:           "field violation",
+			err:            errors.NewFieldError(errors.CodeInvalidInput, "user_id", "must be positive"),
+			wantCode:       codes.InvalidArgument,
+			wantReason:     errors.CodeInvalidInput,
+			wantMessage:    "must be positive",
+			wantViolations: []string{"user_id"},
+		},
+		{
+			name:        "version conflict",
+			err:         errors.NewOrderError(errors.CodeVersionConflict, "order was modified"),
+			wantCode:    codes.Aborted,
+			wantReason:  errors.CodeVersionConflict,
+			wantMessage: "order was modified",
+		},
+		{
+			name:        "unmapped code",
+			err:         errors.NewOrderError(errors.CodeDatabaseError, "database error"),
+			wantCode:    codes.Internal,
+			wantReason:  errors.CodeDatabaseError,
+			wantMessage: "database error",
+		},
+		{
+			name:        "plain error",
+			err:         stderrors.New("connection reset by peer"),
+			wantCode:    codes.Internal,
+			wantReason:  errors.CodeUnknown,
+			wantMessage: errors.DefaultErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(toStatusError(tt.err))
+			if !ok {
+				t.Fatalf("toStatusError() did not return a status error")
+			}
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMessage {
+				t.Errorf("toStatusError() = %s %q, want %s %q", st.Code(), st.Message(), tt.wantCode, tt.wantMessage)
+			}
+
+			var reason string
+			var violations []string
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					if d.Domain != errorDomain {
+						t.Errorf("ErrorInfo domain = %q, want %q", d.Domain, errorDomain)
+					}
+					reason = d.Reason
+				case *errdetails.BadRequest:
+					for _, v := range d.FieldViolations {
+						violations = append(violations, v.Field)
+					}
+				}
+			}
+			if reason != tt.wantReason {
+				t.Errorf("ErrorInfo reason = %q, want %q", reason, tt.wantReason)
+			}
+			if len(violations) != len(tt.wantViolations) || (len(violations) > 0 && violations[0] != tt.wantViolations[0]) {
+				t.Errorf("BadRequest violations = %v, want %v", violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestToStatusErrorNil(t *testing.T) {
+	if err := toStatusError(nil); err != nil {
+		t.Errorf("toStatusError(nil) = %v, want nil", err)
+	}
+}
+
+func TestLegacyErrorUnaryInterceptorPassesThrough(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/unknown.Service/Method"}
+	plain := status.Error(codes.Unavailable, "shutting down")
+
+	_, err := legacyErrorUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, plain
+	})
+	if err != plain {
+		t.Errorf("legacyErrorUnaryInterceptor() error = %v, want the handler error unchanged", err)
+	}
+}
+
+// envelopeResponse stands in for a response returned in the legacy error envelope
+type envelopeResponse struct {
+	success bool
+	code    string
+}
+
+func (r envelopeResponse) GetSuccess() bool { return r.success }
+func (r envelopeResponse) GetCode() string  { return r.code }
+
+func TestMetricsUnaryInterceptorCountsLegacyFailures(t *testing.T) {
+	const method = "/test.Service/LegacyFailure"
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	_, _ = metricsUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return envelopeResponse{success: false, code: errors.CodeOrderNotFound}, nil
+	})
+
+	if got := testutil.ToFloat64(metrics.RPCRequests.WithLabelValues(method, codes.NotFound.String())); got != 1 {
+		t.Errorf("NotFound count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.RPCRequests.WithLabelValues(method, codes.OK.String())); got != 0 {
+		t.Errorf("OK count = %v, want 0", got)
+	}
+}
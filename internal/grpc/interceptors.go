@@ -0,0 +1,261 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"order-service/internal/auth"
+	"order-service/internal/correlation"
+	"order-service/internal/errors"
+	"order-service/internal/metrics"
+)
+
+// authorizationHeader carries the caller's "Bearer <jwt>" access token
+const authorizationHeader = "authorization"
+
+// publicServicePrefixes lists the services callable without a token
+var publicServicePrefixes = []string{
+	"/grpc.reflection.",
+	"/grpc.health.v1.",
+}
+
+// metricsUnaryInterceptor records the count and latency of every RPC by status code.
+// Failures returned in the legacy error envelope are counted by the status code they
+// would otherwise have been returned with.
+func metricsUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err).String()
+	if envelope, ok := resp.(legacyEnvelope); ok && err == nil && !envelope.GetSuccess() {
+		code = grpcCode(envelope.GetCode()).String()
+	}
+	metrics.RPCRequests.WithLabelValues(info.FullMethod, code).Inc()
+	metrics.RPCDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+// legacyErrorUnaryInterceptor returns handler failures as OK responses with
+// Success=false and the ORD_* code, for clients predating gRPC status errors
+func legacyErrorUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if envelope := legacyErrorResponse(info.FullMethod, err); envelope != nil {
+		return envelope, nil
+	}
+	return resp, err
+}
+
+// correlationUnaryInterceptor puts the caller's correlation ID (or a new one) in the
+// request context and echoes it back in the response headers
+func correlationUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := correlationIDFromMetadata(ctx)
+	if id == "" {
+		id = correlation.NewID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(correlation.Header, id))
+
+	return handler(correlation.WithID(ctx, id), req)
+}
+
+func correlationIDFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{correlation.Header, correlation.RequestIDHeader} {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// authUnaryInterceptor rejects calls without a valid access token and puts the
+// authenticated principal in the request context
+func authUnaryInterceptor(verifier *auth.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		principal, err := authenticate(ctx, verifier, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(auth.WithPrincipal(ctx, principal), req)
+	}
+}
+
+// authStreamInterceptor is the streaming counterpart of authUnaryInterceptor
+func authStreamInterceptor(verifier *auth.Verifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		principal, err := authenticate(ss.Context(), verifier, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{
+			ServerStream: ss,
+			ctx:          auth.WithPrincipal(ss.Context(), principal),
+		})
+	}
+}
+
+// contextStream overrides the stream context with one carrying request values
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate verifies the bearer token sent in the request metadata
+func authenticate(ctx context.Context, verifier *auth.Verifier, method string) (*auth.Principal, error) {
+	token := bearerTokenFromMetadata(ctx)
+	if token == "" {
+		return nil, toStatusError(errors.NewOrderError(errors.CodeUnauthorized, "missing bearer token"))
+	}
+
+	principal, err := verifier.Verify(token)
+	if err != nil {
+		log.Printf("🚫 Rejected token for %s: %v", method, err)
+		return nil, toStatusError(errors.NewOrderError(errors.CodeUnauthorized, "invalid access token"))
+	}
+
+	return principal, nil
+}
+
+func bearerTokenFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return ""
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func isPublicMethod(method string) bool {
+	for _, prefix := range publicServicePrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientAllowList restricts RPCs to the mTLS clients allowed to call them
+type clientAllowList struct {
+	// clients are the certificate CNs and SANs allowed; empty allows every client
+	clients map[string]bool
+	// methods are the RPC names restricted to clients
+	methods map[string]bool
+}
+
+func newClientAllowList(clients []string, methods []string) clientAllowList {
+	allowList := clientAllowList{
+		clients: make(map[string]bool, len(clients)),
+		methods: make(map[string]bool, len(methods)),
+	}
+	for _, client := range clients {
+		allowList.clients[client] = true
+	}
+	for _, method := range methods {
+		allowList.methods[method] = true
+	}
+	return allowList
+}
+
+// check rejects calls to a restricted method from clients that are not allowed
+func (a clientAllowList) check(identity *auth.ClientIdentity, fullMethod string) error {
+	if len(a.clients) == 0 || !a.methods[methodName(fullMethod)] {
+		return nil
+	}
+	if identity != nil && identity.Matches(a.clients) {
+		return nil
+	}
+
+	client := "anonymous"
+	if identity != nil {
+		client = identity.String()
+	}
+	log.Printf("🚫 Client %s is not allowed to call %s", client, fullMethod)
+	return toStatusError(errors.NewOrderError(errors.CodeForbidden, "client is not allowed to call "+methodName(fullMethod)))
+}
+
+// clientIdentityUnaryInterceptor puts the verified mTLS client identity in the request
+// context and rejects calls the allow list does not permit
+func clientIdentityUnaryInterceptor(allowList clientAllowList) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity := clientIdentityFromPeer(ctx)
+		if err := allowList.check(identity, info.FullMethod); err != nil {
+			return nil, err
+		}
+		if identity != nil {
+			ctx = auth.WithClientIdentity(ctx, identity)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// clientIdentityStreamInterceptor is the streaming counterpart of clientIdentityUnaryInterceptor
+func clientIdentityStreamInterceptor(allowList clientAllowList) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		identity := clientIdentityFromPeer(ss.Context())
+		if err := allowList.check(identity, info.FullMethod); err != nil {
+			return err
+		}
+		if identity != nil {
+			ss = &contextStream{
+				ServerStream: ss,
+				ctx:          auth.WithClientIdentity(ss.Context(), identity),
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// clientIdentityFromPeer returns the identity of the verified client certificate of
+// the connection, or nil for plaintext and unverified connections
+func clientIdentityFromPeer(ctx context.Context) *auth.ClientIdentity {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return auth.NewClientIdentity(tlsInfo.State.VerifiedChains[0][0])
+}
+
+// methodName returns the RPC name of a full method such as /pkg.Service/Method
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"order-service/internal/auth"
+	"order-service/internal/errors"
+	"order-service/internal/metrics"
+)
+
+// peerContext returns a context for a connection authenticated with a client
+// certificate for commonName, or a plaintext connection when commonName is empty
+func peerContext(commonName string) context.Context {
+	p := &peer.Peer{}
+	if commonName != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		p.AuthInfo = credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	}
+	return peer.NewContext(context.Background(), p)
+}
+
+func TestClientIdentityUnaryInterceptor(t *testing.T) {
+	methods := []string{"UpdateOrderStatus", "CancelOrder", "CreateRefund", "UpdateRefundStatus"}
+
+	tests := []struct {
+		name     string
+		clients  []string
+		client   string
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "allowed client", clients: []string{"admin-service"}, client: "admin-service", method: "CancelOrder", wantCode: codes.OK},
+		{name: "client not allow-listed", clients: []string{"admin-service"}, client: "storefront", method: "CancelOrder", wantCode: codes.PermissionDenied},
+		{name: "refund by client not allow-listed", clients: []string{"admin-service"}, client: "storefront", method: "CreateRefund", wantCode: codes.PermissionDenied},
+		{name: "anonymous client", clients: []string{"admin-service"}, method: "UpdateOrderStatus", wantCode: codes.PermissionDenied},
+		{name: "unrestricted method", clients: []string{"admin-service"}, client: "storefront", method: "GetOrder", wantCode: codes.OK},
+		{name: "no allow list", client: "storefront", method: "CancelOrder", wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := clientIdentityUnaryInterceptor(newClientAllowList(tt.clients, methods))
+			info := &grpc.UnaryServerInfo{FullMethod: "/order.OrderGRPCService/" + tt.method}
+
+			var identity *auth.ClientIdentity
+			_, err := interceptor(peerContext(tt.client), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				identity = auth.ClientIdentityFromContext(ctx)
+				return nil, nil
+			})
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("interceptor code = %s, want %s (error %v)", code, tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && tt.client != "" && (identity == nil || identity.CommonName != tt.client) {
+				t.Errorf("handler identity = %+v, want %s", identity, tt.client)
+			}
+		})
+	}
+}
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     interface{}
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "success", resp: envelopeResponse{success: true}, wantCode: codes.OK},
+		{name: "status error", err: status.Error(codes.InvalidArgument, "bad request"), wantCode: codes.InvalidArgument},
+		{name: "legacy envelope failure", resp: envelopeResponse{code: errors.CodeVersionConflict}, wantCode: codes.Aborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := "/test.Service/" + strings.ReplaceAll(tt.name, " ", "")
+			info := &grpc.UnaryServerInfo{FullMethod: method}
+
+			_, _ = metricsUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return tt.resp, tt.err
+			})
+
+			if got := testutil.ToFloat64(metrics.RPCRequests.WithLabelValues(method, tt.wantCode.String())); got != 1 {
+				t.Errorf("%s count = %v, want 1", tt.wantCode, got)
+			}
+		})
+	}
+}
@@ -6,15 +6,25 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/grpc/metadata"
 
 	commonGrpc "order-service/go-proto/modules/common"
 	orderGrpc "order-service/go-proto/modules/order"
 	grpc "order-service/go-proto/services"
+	"order-service/internal/auth"
 	"order-service/internal/database/db"
 	"order-service/internal/errors"
 	"order-service/internal/service"
 )
 
+const (
+	// idempotencyKeyHeader is the metadata key clients use to make CreateOrder retries safe
+	idempotencyKeyHeader = "idempotency-key"
+	// actorHeader is the metadata key identifying who performs a mutation
+	actorHeader  = "x-actor"
+	unknownActor = "unknown"
+)
+
 type OrderGrpcHandler struct {
 	grpc.UnimplementedOrderGRPCServiceServer
 	orderService *service.OrderService
@@ -29,38 +39,31 @@ func NewOrderGrpcHandler(orderService *service.OrderService) *OrderGrpcHandler {
 func (h *OrderGrpcHandler) CreateOrder(ctx context.Context, req *orderGrpc.CreateOrderRequest) (*orderGrpc.CreateOrderResponse, error) {
 	log.Printf("📥 Received CreateOrder request: UserID=%d, Products=%d", req.UserId, len(req.Products))
 
-	products := make([]struct {
-		ProductID int32
-		Quantity  int32
-		Price     float64
-	}, len(req.Products))
+	products := make([]service.OrderItemParams, len(req.Products))
 
 	for i, p := range req.Products {
-		products[i] = struct {
-			ProductID int32
-			Quantity  int32
-			Price     float64
-		}{
-			ProductID: p.ProductId,
-			Quantity:  p.Quantity,
-			Price:     p.Price,
+		products[i] = service.OrderItemParams{
+			ProductID:  p.ProductId,
+			Quantity:   p.Quantity,
+			Price:      p.Price,
+			PriceMinor: p.PriceMinor,
 		}
 	}
 
 	order, orderProducts, err := h.orderService.CreateOrder(ctx, service.CreateOrderParams{
-		UserID:      req.UserId,
-		TotalAmount: req.TotalAmount,
-		Products:    products,
+		UserID:           req.UserId,
+		Currency:         req.Currency,
+		TotalAmount:      req.TotalAmount,
+		TotalAmountMinor: req.TotalAmountMinor,
+		Products:         products,
+		IdempotencyKey:   idempotencyKeyFromContext(ctx),
+		Actor:            actorFromContext(ctx),
+		Source:           service.SourceGRPC,
 	})
 
 	if err != nil {
-		orderErr := errors.GetError(err)
 		log.Printf("❌ Failed to create order: %v", err)
-		return &orderGrpc.CreateOrderResponse{
-			Success: false,
-			Message: orderErr.Message,
-			Code:    orderErr.ErrorCode,
-		}, nil
+		return nil, toStatusError(err)
 	}
 
 	return &orderGrpc.CreateOrderResponse{
@@ -76,12 +79,7 @@ func (h *OrderGrpcHandler) GetOrder(ctx context.Context, req *orderGrpc.GetOrder
 	order, orderProducts, err := h.orderService.GetOrder(ctx, req.Id)
 
 	if err != nil {
-		orderErr := errors.GetError(err)
-		return &orderGrpc.GetOrderResponse{
-			Success: false,
-			Message: orderErr.Message,
-			Code:    orderErr.ErrorCode,
-		}, nil
+		return nil, toStatusError(err)
 	}
 
 	return &orderGrpc.GetOrderResponse{
@@ -95,19 +93,29 @@ func (h *OrderGrpcHandler) GetOrder(ctx context.Context, req *orderGrpc.GetOrder
 func (h *OrderGrpcHandler) GetOrdersByUser(ctx context.Context, req *orderGrpc.GetOrdersByUserRequest) (*orderGrpc.GetOrdersByUserResponse, error) {
 	log.Printf("📥 Received GetOrdersByUser request: UserID=%d", req.UserId)
 
-	orders, total, totalPages, err := h.orderService.GetOrdersByUserId(ctx, req.UserId, req.Limit, req.Page)
+	orders, page, err := h.orderService.GetOrdersByUserId(ctx, req.UserId, service.PageParams{
+		Limit:        req.Limit,
+		Page:         req.Page,
+		Cursor:       req.Cursor,
+		IncludeTotal: req.IncludeTotal,
+	})
 	if err != nil {
-		orderErr := errors.GetError(err)
-		return &orderGrpc.GetOrdersByUserResponse{
-			Success: false,
-			Message: orderErr.Message,
-			Code:    orderErr.ErrorCode,
-		}, nil
+		return nil, toStatusError(err)
 	}
 
 	protoOrders := make([]*orderGrpc.Order, len(orders))
-	for i, o := range orders {
-		protoOrders[i] = orderToProtoSimple(&o)
+	if req.IncludeProducts {
+		products, err := h.orderService.GetProductsForOrders(ctx, orders)
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		for i, o := range orders {
+			protoOrders[i] = orderToProto(&o, products[o.ID])
+		}
+	} else {
+		for i, o := range orders {
+			protoOrders[i] = orderToProtoSimple(&o)
+		}
 	}
 
 	return &orderGrpc.GetOrdersByUserResponse{
@@ -115,28 +123,78 @@ func (h *OrderGrpcHandler) GetOrdersByUser(ctx context.Context, req *orderGrpc.G
 		Message: "Orders found",
 		Code:    "SUCCESS",
 		Data: &orderGrpc.GetOrdersByUserResponse_Data{
-			Orders: protoOrders,
-			Pagination: &commonGrpc.Pagination{
-				Total:      total,
-				TotalPages: totalPages,
-				Limit:      req.Limit,
-				Page:       req.Page,
-			},
+			Orders:     protoOrders,
+			Pagination: paginationToProto(page),
+		},
+	}, nil
+}
+
+func (h *OrderGrpcHandler) SearchOrders(ctx context.Context, req *orderGrpc.SearchOrdersRequest) (*orderGrpc.SearchOrdersResponse, error) {
+	log.Printf("📥 Received SearchOrders request: UserID=%d, Statuses=%v, ProductID=%d", req.UserId, req.Statuses, req.ProductId)
+
+	orders, page, err := h.searchOrders(ctx, req)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	protoOrders := make([]*orderGrpc.Order, len(orders))
+	for i, o := range orders {
+		protoOrders[i] = orderToProtoSimple(&o)
+	}
+
+	return &orderGrpc.SearchOrdersResponse{
+		Success: true,
+		Message: "Orders found",
+		Code:    "SUCCESS",
+		Data: &orderGrpc.SearchOrdersResponse_Data{
+			Orders:     protoOrders,
+			Pagination: paginationToProto(page),
 		},
 	}, nil
 }
 
+func (h *OrderGrpcHandler) searchOrders(ctx context.Context, req *orderGrpc.SearchOrdersRequest) ([]db.Order, service.PageInfo, error) {
+	createdFrom, err := parseTimestamp("created_from", req.CreatedFrom)
+	if err != nil {
+		return nil, service.PageInfo{}, err
+	}
+	createdTo, err := parseTimestamp("created_to", req.CreatedTo)
+	if err != nil {
+		return nil, service.PageInfo{}, err
+	}
+
+	return h.orderService.SearchOrders(ctx, service.SearchOrdersParams{
+		UserID:        req.UserId,
+		ProductID:     req.ProductId,
+		Statuses:      req.Statuses,
+		CreatedFrom:   createdFrom,
+		CreatedTo:     createdTo,
+		MinTotalMinor: req.MinTotalMinor,
+		MaxTotalMinor: req.MaxTotalMinor,
+		SortBy:        req.SortBy,
+		SortDirection: req.SortDirection,
+		Page: service.PageParams{
+			Limit:        req.Limit,
+			Page:         req.Page,
+			Cursor:       req.Cursor,
+			IncludeTotal: req.IncludeTotal,
+		},
+	})
+}
+
 func (h *OrderGrpcHandler) UpdateOrderStatus(ctx context.Context, req *orderGrpc.UpdateOrderStatusRequest) (*orderGrpc.UpdateOrderStatusResponse, error) {
 	log.Printf("📥 Received UpdateOrderStatus request: ID=%d, Status=%s", req.Id, req.Status)
 
-	order, err := h.orderService.UpdateOrderStatus(ctx, req.Id, req.Status)
+	order, err := h.orderService.UpdateOrderStatus(ctx, service.UpdateOrderStatusParams{
+		OrderID:         req.Id,
+		Status:          req.Status,
+		Actor:           actorFromContext(ctx),
+		Source:          service.SourceGRPC,
+		Reason:          req.Reason,
+		ExpectedVersion: req.ExpectedVersion,
+	})
 	if err != nil {
-		orderErr := errors.GetError(err)
-		return &orderGrpc.UpdateOrderStatusResponse{
-			Success: false,
-			Message: orderErr.Message,
-			Code:    orderErr.ErrorCode,
-		}, nil
+		return nil, toStatusError(err)
 	}
 
 	return &orderGrpc.UpdateOrderStatusResponse{
@@ -147,46 +205,154 @@ func (h *OrderGrpcHandler) UpdateOrderStatus(ctx context.Context, req *orderGrpc
 	}, nil
 }
 
+func (h *OrderGrpcHandler) CancelOrder(ctx context.Context, req *orderGrpc.CancelOrderRequest) (*orderGrpc.CancelOrderResponse, error) {
+	log.Printf("📥 Received CancelOrder request: ID=%d, Reason=%s", req.Id, req.ReasonCode)
+
+	actor := actorFromContext(ctx)
+	if req.Actor != "" && auth.FromContext(ctx) == nil {
+		actor = req.Actor
+	}
+
+	order, err := h.orderService.CancelOrder(ctx, service.CancelOrderParams{
+		OrderID:         req.Id,
+		ReasonCode:      req.ReasonCode,
+		Note:            req.Note,
+		Actor:           actor,
+		Source:          service.SourceGRPC,
+		ExpectedVersion: req.ExpectedVersion,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &orderGrpc.CancelOrderResponse{
+		Success: true,
+		Message: "Order cancelled successfully",
+		Code:    "SUCCESS",
+		Data:    orderToProtoSimple(order),
+	}, nil
+}
+
+func (h *OrderGrpcHandler) GetOrderHistory(ctx context.Context, req *orderGrpc.GetOrderHistoryRequest) (*orderGrpc.GetOrderHistoryResponse, error) {
+	log.Printf("📥 Received GetOrderHistory request: OrderID=%d", req.OrderId)
+
+	history, err := h.orderService.GetOrderHistory(ctx, req.OrderId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	entries := make([]*orderGrpc.OrderStatusHistoryEntry, len(history))
+	for i, entry := range history {
+		entries[i] = &orderGrpc.OrderStatusHistoryEntry{
+			Id:         entry.ID,
+			FromStatus: entry.FromStatus.String,
+			ToStatus:   entry.ToStatus,
+			Actor:      entry.Actor,
+			Source:     entry.Source,
+			Reason:     entry.Reason.String,
+			CreatedAt:  formatTimestamp(entry.CreatedAt),
+		}
+	}
+
+	return &orderGrpc.GetOrderHistoryResponse{
+		Success: true,
+		Message: "Order history found",
+		Code:    "SUCCESS",
+		Data: &orderGrpc.GetOrderHistoryResponse_Data{
+			Entries: entries,
+		},
+	}, nil
+}
+
 // Helper functions
 
-func orderToProto(order *db.Order, products []db.OrderProduct) *orderGrpc.Order {
-	return &orderGrpc.Order{
-		Id:          order.ID,
-		UserId:      order.UserID,
-		Status:      order.Status,
-		TotalAmount: order.TotalAmount,
-		Products:    productsToProto(products),
-		CreatedAt:   formatTimestamp(order.CreatedAt),
-		UpdatedAt:   formatTimestamp(order.UpdatedAt),
+// actorFromContext returns the authenticated subject, or else the caller identity
+// sent as gRPC metadata
+func actorFromContext(ctx context.Context) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return principal.Subject
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return unknownActor
+	}
+	if values := md.Get(actorHeader); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	return unknownActor
+}
+
+// idempotencyKeyFromContext reads the optional idempotency key sent as gRPC metadata
+func idempotencyKeyFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+		return values[0]
 	}
+	return ""
+}
+
+func orderToProto(order *db.Order, products []db.OrderProduct) *orderGrpc.Order {
+	protoOrder := orderToProtoSimple(order)
+	protoOrder.Products = productsToProto(products, order.Currency)
+	return protoOrder
 }
 
 func orderToProtoSimple(order *db.Order) *orderGrpc.Order {
 	return &orderGrpc.Order{
-		Id:          order.ID,
-		UserId:      order.UserID,
-		Status:      order.Status,
-		TotalAmount: order.TotalAmount,
-		CreatedAt:   formatTimestamp(order.CreatedAt),
-		UpdatedAt:   formatTimestamp(order.UpdatedAt),
+		Id:               order.ID,
+		UserId:           order.UserID,
+		Status:           order.Status,
+		TotalAmountMinor: order.TotalAmountMinor,
+		Currency:         order.Currency,
+		Version:          order.Version,
+		TotalAmount:      service.NewMoney(order.TotalAmountMinor, order.Currency).Float64(),
+		CreatedAt:        formatTimestamp(order.CreatedAt),
+		UpdatedAt:        formatTimestamp(order.UpdatedAt),
 	}
 }
 
-func productsToProto(products []db.OrderProduct) []*orderGrpc.OrderProduct {
+func productsToProto(products []db.OrderProduct, currency string) []*orderGrpc.OrderProduct {
 	protoProducts := make([]*orderGrpc.OrderProduct, len(products))
 
 	for i, p := range products {
 		protoProducts[i] = &orderGrpc.OrderProduct{
-			Id:        p.ID,
-			ProductId: p.ProductID,
-			Quantity:  p.Quantity,
-			Price:     p.Price,
+			Id:         p.ID,
+			ProductId:  p.ProductID,
+			Quantity:   p.Quantity,
+			PriceMinor: p.PriceMinor,
+			Price:      service.NewMoney(p.PriceMinor, currency).Float64(),
 		}
 	}
 
 	return protoProducts
 }
 
+func paginationToProto(page service.PageInfo) *commonGrpc.Pagination {
+	return &commonGrpc.Pagination{
+		Total:      page.Total,
+		TotalPages: page.TotalPages,
+		Limit:      page.Limit,
+		Page:       page.Page,
+		NextCursor: page.NextCursor,
+		HasTotal:   page.HasTotal,
+	}
+}
+
+// parseTimestamp parses an optional RFC 3339 request field; empty means unset
+func parseTimestamp(field string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.NewFieldError(errors.CodeInvalidInput, field, "invalid timestamp, expected RFC 3339: "+value)
+	}
+	return &t, nil
+}
+
 func formatTimestamp(t pgtype.Timestamp) string {
 	if t.Valid {
 		return t.Time.UTC().Format(time.RFC3339)
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"context"
+	"log"
+
+	orderGrpc "order-service/go-proto/modules/order"
+	"order-service/internal/database/db"
+	"order-service/internal/service"
+)
+
+func (h *OrderGrpcHandler) CreateRefund(ctx context.Context, req *orderGrpc.CreateRefundRequest) (*orderGrpc.CreateRefundResponse, error) {
+	log.Printf("📥 Received CreateRefund request: OrderID=%d, Items=%d", req.OrderId, len(req.Items))
+
+	items := make([]service.RefundItemParams, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = service.RefundItemParams{
+			OrderProductID: item.OrderProductId,
+			Quantity:       item.Quantity,
+			AmountMinor:    item.AmountMinor,
+		}
+	}
+
+	refund, refundItems, err := h.orderService.CreateRefund(ctx, service.CreateRefundParams{
+		OrderID:         req.OrderId,
+		Reason:          req.Reason,
+		Items:           items,
+		Actor:           actorFromContext(ctx),
+		Source:          service.SourceGRPC,
+		ExpectedVersion: req.ExpectedVersion,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &orderGrpc.CreateRefundResponse{
+		Success: true,
+		Message: "Refund requested successfully",
+		Code:    "SUCCESS",
+		Data:    refundToProto(refund, refundItems),
+	}, nil
+}
+
+func (h *OrderGrpcHandler) UpdateRefundStatus(ctx context.Context, req *orderGrpc.UpdateRefundStatusRequest) (*orderGrpc.UpdateRefundStatusResponse, error) {
+	log.Printf("📥 Received UpdateRefundStatus request: ID=%d, Status=%s", req.Id, req.Status)
+
+	refund, refundItems, err := h.orderService.UpdateRefundStatus(ctx, service.UpdateRefundStatusParams{
+		RefundID:        req.Id,
+		Status:          req.Status,
+		FailureReason:   req.FailureReason,
+		Actor:           actorFromContext(ctx),
+		Source:          service.SourceGRPC,
+		ExpectedVersion: req.ExpectedVersion,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &orderGrpc.UpdateRefundStatusResponse{
+		Success: true,
+		Message: "Refund status updated successfully",
+		Code:    "SUCCESS",
+		Data:    refundToProto(refund, refundItems),
+	}, nil
+}
+
+func (h *OrderGrpcHandler) GetOrderRefunds(ctx context.Context, req *orderGrpc.GetOrderRefundsRequest) (*orderGrpc.GetOrderRefundsResponse, error) {
+	log.Printf("📥 Received GetOrderRefunds request: OrderID=%d", req.OrderId)
+
+	refunds, refundItems, err := h.orderService.GetOrderRefunds(ctx, req.OrderId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	itemsByRefund := make(map[int32][]db.RefundItem, len(refunds))
+	for _, item := range refundItems {
+		itemsByRefund[item.RefundID] = append(itemsByRefund[item.RefundID], item)
+	}
+
+	protoRefunds := make([]*orderGrpc.Refund, len(refunds))
+	for i := range refunds {
+		protoRefunds[i] = refundToProto(&refunds[i], itemsByRefund[refunds[i].ID])
+	}
+
+	return &orderGrpc.GetOrderRefundsResponse{
+		Success: true,
+		Message: "Refunds found",
+		Code:    "SUCCESS",
+		Data: &orderGrpc.GetOrderRefundsResponse_Data{
+			Refunds: protoRefunds,
+		},
+	}, nil
+}
+
+func refundToProto(refund *db.Refund, items []db.RefundItem) *orderGrpc.Refund {
+	protoItems := make([]*orderGrpc.RefundItem, len(items))
+	for i, item := range items {
+		protoItems[i] = &orderGrpc.RefundItem{
+			Id:             item.ID,
+			OrderProductId: item.OrderProductID,
+			Quantity:       item.Quantity,
+			AmountMinor:    item.AmountMinor,
+		}
+	}
+
+	return &orderGrpc.Refund{
+		Id:            refund.ID,
+		OrderId:       refund.OrderID,
+		Status:        refund.Status,
+		AmountMinor:   refund.AmountMinor,
+		Currency:      refund.Currency,
+		Reason:        refund.Reason,
+		FailureReason: refund.FailureReason.String,
+		Actor:         refund.Actor,
+		Items:         protoItems,
+		CreatedAt:     formatTimestamp(refund.CreatedAt),
+		UpdatedAt:     formatTimestamp(refund.UpdatedAt),
+	}
+}
@@ -1,32 +1,121 @@
 package grpc
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	orderGrpc "order-service/go-proto/services"
+	"order-service/internal/auth"
+	"order-service/internal/config"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
-func StartGRPCServer(port int, handler *OrderGrpcHandler) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
-	if err != nil {
-		return fmt.Errorf("failed to listen: %w", err)
+// Server is the order service gRPC server
+type Server struct {
+	port     int
+	server   *grpc.Server
+	reloader *certReloader
+}
+
+// NewServer builds the gRPC server for handler and the grpc.health.v1 service. A nil
+// verifier disables authentication.
+func NewServer(cfg *config.Config, handler *OrderGrpcHandler, verifier *auth.Verifier, healthServer healthpb.HealthServer) (*Server, error) {
+	if len(cfg.GRPCAllowedClients) > 0 && (!cfg.GRPCTLSEnabled || cfg.GRPCTLSClientCAFile == "") {
+		return nil, fmt.Errorf("GRPC_ALLOWED_CLIENTS requires mTLS (GRPC_TLS_ENABLED and GRPC_TLS_CLIENT_CA_FILE)")
+	}
+
+	allowList := newClientAllowList(cfg.GRPCAllowedClients, cfg.GRPCAllowListedMethods)
+
+	unary := []grpc.UnaryServerInterceptor{metricsUnaryInterceptor, correlationUnaryInterceptor, clientIdentityUnaryInterceptor(allowList)}
+	stream := []grpc.StreamServerInterceptor{clientIdentityStreamInterceptor(allowList)}
+	if verifier != nil {
+		unary = append(unary, authUnaryInterceptor(verifier))
+		stream = append(stream, authStreamInterceptor(verifier))
+	}
+	if cfg.LegacyErrorEnvelope {
+		// Innermost, so only errors returned by the handlers are wrapped
+		unary = append(unary, legacyErrorUnaryInterceptor)
+	}
+
+	opts := []grpc.ServerOption{
+		// Continues the caller's trace and opens a server span for every RPC
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+
+	srv := &Server{port: cfg.GRPCPort}
+
+	if cfg.GRPCTLSEnabled {
+		reloader, err := newCertReloader(cfg.GRPCTLSCertFile, cfg.GRPCTLSKeyFile, cfg.GRPCTLSClientCAFile, cfg.GRPCTLSReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		srv.reloader = reloader
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(reloader.tlsConfig())))
+		if cfg.GRPCTLSClientCAFile != "" {
+			log.Println("🔒 gRPC mTLS enabled")
+		} else {
+			log.Println("🔒 gRPC TLS enabled")
+		}
 	}
 
-	s := grpc.NewServer()
-	orderGrpc.RegisterOrderGRPCServiceServer(s, handler)
+	srv.server = grpc.NewServer(opts...)
+	orderGrpc.RegisterOrderGRPCServiceServer(srv.server, handler)
+	healthpb.RegisterHealthServer(srv.server, healthServer)
 
 	// Enable reflection for testing with grpcurl
-	reflection.Register(s)
+	reflection.Register(srv.server)
 
-	log.Printf("🚀 gRPC server listening on :%d", port)
+	return srv, nil
+}
+
+// Serve accepts connections until Shutdown is called
+func (s *Server) Serve() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	if s.reloader != nil {
+		s.reloader.Start(context.Background())
+	}
 
-	if err := s.Serve(lis); err != nil {
+	log.Printf("🚀 gRPC server listening on :%d", s.port)
+
+	if err := s.server.Serve(lis); err != nil {
 		return fmt.Errorf("failed to serve: %w", err)
 	}
 
 	return nil
 }
+
+// Shutdown stops accepting new RPCs and waits for in-flight ones to finish. RPCs
+// still running when ctx is done are cancelled.
+func (s *Server) Shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("✅ gRPC server drained")
+	case <-ctx.Done():
+		log.Println("⚠️ Shutdown deadline exceeded; cancelling in-flight RPCs")
+		s.server.Stop()
+		<-done
+	}
+
+	if s.reloader != nil {
+		s.reloader.Stop()
+	}
+}
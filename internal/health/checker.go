@@ -0,0 +1,165 @@
+package health
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"order-service/internal/database"
+	"order-service/internal/kafka"
+)
+
+// LivenessService is the grpc.health.v1 service name answering liveness probes. The
+// empty service name answers readiness probes.
+const LivenessService = "liveness"
+
+// Dependency names reported by the readiness probe
+const (
+	DependencyDatabase = "database"
+	DependencyKafka    = "kafka"
+)
+
+// Checker probes the database and Kafka in the background and publishes the result
+// through the gRPC health service. The process is live as long as it runs; it is
+// ready only while every dependency answers and it is not shutting down.
+type Checker struct {
+	// probes checks each dependency by name
+	probes   map[string]func(context.Context) error
+	interval time.Duration
+	timeout  time.Duration
+	server   *grpchealth.Server
+
+	mu           sync.RWMutex
+	failures     map[string]string
+	shuttingDown bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewChecker(db *database.DB, producer *kafka.Producer, interval time.Duration, timeout time.Duration) *Checker {
+	return newChecker(map[string]func(context.Context) error{
+		DependencyDatabase: db.Ping,
+		DependencyKafka:    producer.Ping,
+	}, interval, timeout)
+}
+
+func newChecker(probes map[string]func(context.Context) error, interval time.Duration, timeout time.Duration) *Checker {
+	c := &Checker{
+		probes:   probes,
+		interval: interval,
+		timeout:  timeout,
+		server:   grpchealth.NewServer(),
+		// Not ready until the first probe succeeds
+		failures: map[string]string{"server": "starting"},
+	}
+	c.server.SetServingStatus(LivenessService, healthpb.HealthCheckResponse_SERVING)
+	c.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return c
+}
+
+// Server returns the grpc.health.v1 implementation to register on the gRPC server
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Start probes the dependencies immediately, then in a background goroutine every
+// interval until Stop is called
+func (c *Checker) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.check(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop signals the checker to exit and waits for it
+func (c *Checker) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+// Shutdown reports every service as NOT_SERVING so load balancers stop routing new
+// requests while in-flight ones drain
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	c.shuttingDown = true
+	c.mu.Unlock()
+
+	c.server.Shutdown()
+}
+
+func (c *Checker) check(ctx context.Context) {
+	failures := make(map[string]string)
+	for name, probe := range c.probes {
+		probeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := probe(probeCtx)
+		cancel()
+		if err != nil {
+			failures[name] = err.Error()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shuttingDown || ctx.Err() != nil {
+		return
+	}
+
+	wasReady := len(c.failures) == 0
+	c.failures = failures
+
+	if len(failures) == 0 {
+		if !wasReady {
+			log.Println("✅ Dependencies healthy; serving")
+		}
+		c.server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		return
+	}
+
+	for name, reason := range failures {
+		log.Printf("❌ Health check failed: %s: %s", name, reason)
+	}
+	c.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Ready reports whether the service can handle requests, and otherwise why not
+func (c *Checker) Ready() (bool, map[string]string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.shuttingDown {
+		return false, map[string]string{"server": "shutting down"}
+	}
+	if len(c.failures) == 0 {
+		return true, nil
+	}
+
+	failures := make(map[string]string, len(c.failures))
+	for name, reason := range c.failures {
+		failures[name] = reason
+	}
+	return false, failures
+}
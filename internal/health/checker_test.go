@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func probeResult(err error) func(context.Context) error {
+	return func(context.Context) error { return err }
+}
+
+// servingStatus returns the grpc.health.v1 status the checker reports for service
+func servingStatus(t *testing.T, c *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := c.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q) error = %v", service, err)
+	}
+	return resp.Status
+}
+
+func TestCheckerReadiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		probes       map[string]func(context.Context) error
+		wantReady    bool
+		wantFailures map[string]string
+	}{
+		{
+			name: "every dependency healthy",
+			probes: map[string]func(context.Context) error{
+				DependencyDatabase: probeResult(nil),
+				DependencyKafka:    probeResult(nil),
+			},
+			wantReady: true,
+		},
+		{
+			name: "database down",
+			probes: map[string]func(context.Context) error{
+				DependencyDatabase: probeResult(stderrors.New("connection refused")),
+				DependencyKafka:    probeResult(nil),
+			},
+			wantFailures: map[string]string{DependencyDatabase: "connection refused"},
+		},
+		{
+			name: "every dependency down",
+			probes: map[string]func(context.Context) error{
+				DependencyDatabase: probeResult(stderrors.New("connection refused")),
+				DependencyKafka:    probeResult(stderrors.New("no brokers")),
+			},
+			wantFailures: map[string]string{DependencyDatabase: "connection refused", DependencyKafka: "no brokers"},
+		},
+		{
+			name: "probe timing out",
+			probes: map[string]func(context.Context) error{
+				DependencyDatabase: probeResult(nil),
+				DependencyKafka: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			wantFailures: map[string]string{DependencyKafka: context.DeadlineExceeded.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newChecker(tt.probes, time.Hour, 10*time.Millisecond)
+			c.check(context.Background())
+
+			ready, failures := c.Ready()
+			if ready != tt.wantReady || !reflect.DeepEqual(failures, tt.wantFailures) {
+				t.Errorf("Ready() = %v, %v; want %v, %v", ready, failures, tt.wantReady, tt.wantFailures)
+			}
+
+			want := healthpb.HealthCheckResponse_NOT_SERVING
+			if tt.wantReady {
+				want = healthpb.HealthCheckResponse_SERVING
+			}
+			if got := servingStatus(t, c, ""); got != want {
+				t.Errorf("readiness status = %s, want %s", got, want)
+			}
+			if got := servingStatus(t, c, LivenessService); got != healthpb.HealthCheckResponse_SERVING {
+				t.Errorf("liveness status = %s, want SERVING", got)
+			}
+		})
+	}
+}
+
+func TestCheckerNotReadyBeforeFirstCheck(t *testing.T) {
+	c := newChecker(map[string]func(context.Context) error{DependencyDatabase: probeResult(nil)}, time.Hour, time.Second)
+
+	if ready, failures := c.Ready(); ready || failures["server"] != "starting" {
+		t.Errorf("Ready() = %v, %v; want not ready while starting", ready, failures)
+	}
+	if got := servingStatus(t, c, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("readiness status = %s, want NOT_SERVING", got)
+	}
+}
+
+func TestCheckerShutdown(t *testing.T) {
+	c := newChecker(map[string]func(context.Context) error{DependencyDatabase: probeResult(nil)}, time.Hour, time.Second)
+	c.check(context.Background())
+
+	c.Shutdown()
+	// A check after shutdown must not report the service ready again
+	c.check(context.Background())
+
+	if ready, failures := c.Ready(); ready || failures["server"] != "shutting down" {
+		t.Errorf("Ready() = %v, %v; want not ready while shutting down", ready, failures)
+	}
+	for _, service := range []string{"", LivenessService} {
+		if got := servingStatus(t, c, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("status of %q = %s, want NOT_SERVING", service, got)
+		}
+	}
+}
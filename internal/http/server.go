@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"order-service/internal/health"
+)
+
+// Server is the plain HTTP listener for metrics scrapes, and for load balancers and
+// probes that cannot speak gRPC
+type Server struct {
+	server *http.Server
+}
+
+func NewServer(port int, checker *health.Checker) *Server {
+	mux := http.NewServeMux()
+
+	// The process is live as long as it can answer
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready, failures := checker.Ready()
+		if !ready {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"status":   "unavailable",
+				"failures": failures,
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+	})
+
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	return &Server{
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Serve accepts connections until Shutdown is called
+func (s *Server) Serve() error {
+	log.Printf("🚀 HTTP server listening on %s", s.server.Addr)
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// Shutdown stops the listener and waits for open requests until ctx is done
+func (s *Server) Shutdown(ctx context.Context) {
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("❌ Failed to shut down HTTP server: %v", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,233 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-service/internal/config"
+	"order-service/internal/tracing"
+)
+
+// fetchRetryDelay is how long to wait before fetching again after a fetch error
+const fetchRetryDelay = 1 * time.Second
+
+// Handler processes a consumed message. Returning nil commits the message offset.
+// Other errors are retried according to the consumer's retry policy; once retries are
+// exhausted, or immediately for errors wrapped with Permanent, the message is sent to
+// the dead-letter topic and its offset committed.
+type Handler func(ctx context.Context, msg Message) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks a handler error that retrying cannot fix, such as a malformed payload
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Consumer reads messages from a set of topics as part of a consumer group and
+// commits each offset only after the handler has processed the message
+type Consumer struct {
+	reader     *kafka.Reader
+	handler    Handler
+	retry      RetryPolicy
+	deadLetter *Producer
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewConsumer(cfg *config.Config, groupID string, topics []string, handler Handler, deadLetter *Producer) (*Consumer, error) {
+	if len(cfg.KafkaBrokers) == 0 {
+		return nil, fmt.Errorf("no kafka brokers configured")
+	}
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("no topics to consume")
+	}
+
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.KafkaBrokers,
+		GroupID:     groupID,
+		GroupTopics: topics,
+		Dialer:      dialer,
+		// A new group starts at the end of the topics; history is not replayed
+		StartOffset: kafka.LastOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+	})
+
+	return &Consumer{
+		reader:  reader,
+		handler: handler,
+		retry: RetryPolicy{
+			MaxAttempts:    cfg.KafkaConsumerMaxAttempts,
+			InitialBackoff: cfg.KafkaConsumerRetryInitialBackoff,
+			MaxBackoff:     cfg.KafkaConsumerRetryMaxBackoff,
+		},
+		deadLetter: deadLetter,
+	}, nil
+}
+
+// Start consumes messages in a background goroutine until Stop is called
+func (c *Consumer) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run(ctx)
+	}()
+
+	log.Printf("👂 Kafka consumer started (group=%s, topics=%v)", c.reader.Config().GroupID, c.reader.Config().GroupTopics)
+}
+
+// Stop waits for the message in progress, then closes the reader
+func (c *Consumer) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+
+	if err := c.reader.Close(); err != nil {
+		log.Printf("Error closing kafka reader: %v", err)
+	}
+	log.Println("✅ Kafka consumer stopped")
+}
+
+func (c *Consumer) run(ctx context.Context) {
+	for {
+		m, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("❌ Failed to fetch kafka message: %v", err)
+			if !sleep(ctx, fetchRetryDelay) {
+				return
+			}
+			continue
+		}
+
+		msg := fromKafkaMessage(m)
+		if !c.handle(ctx, msg) {
+			// Stopped before the message was processed; it is redelivered on restart
+			return
+		}
+
+		// The message being handled is finished even if the consumer is stopping
+		if err := c.reader.CommitMessages(context.WithoutCancel(ctx), m); err != nil {
+			log.Printf("❌ Failed to commit offset %d on %s[%d]: %v", m.Offset, m.Topic, m.Partition, err)
+		}
+	}
+}
+
+// handle runs the handler until it succeeds, or dead-letters the message once it fails
+// permanently or runs out of attempts. It returns false if the consumer was stopped
+// before the message was dealt with.
+func (c *Consumer) handle(ctx context.Context, msg Message) bool {
+	handlerCtx, span := tracing.Tracer().Start(tracing.Extract(context.WithoutCancel(ctx), msg.Headers), msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+		),
+	)
+	defer span.End()
+
+	for attempt := 1; ; attempt++ {
+		err := c.handler(handlerCtx, msg)
+		if err == nil {
+			return true
+		}
+
+		log.Printf("⚠️ Failed to handle message at offset %d on %s[%d] (attempt %d): %v", msg.Offset, msg.Topic, msg.Partition, attempt, err)
+		span.RecordError(err)
+
+		if IsPermanent(err) || c.retry.Exhausted(attempt) {
+			return c.sendToDeadLetter(ctx, msg, err, attempt)
+		}
+		if !sleep(ctx, c.retry.Backoff(attempt)) {
+			return false
+		}
+	}
+}
+
+// sendToDeadLetter publishes the message to the dead-letter topic, retrying until it
+// succeeds so the offset is never committed for a message that was not captured
+func (c *Consumer) sendToDeadLetter(ctx context.Context, msg Message, cause error, attempts int) bool {
+	for retry := 1; ; retry++ {
+		err := c.deadLetter.PublishDeadLetter(context.WithoutCancel(ctx), msg, cause, attempts, DeadLetterSourceConsumer)
+		if err == nil {
+			return true
+		}
+
+		log.Printf("❌ Failed to dead-letter message at offset %d on %s[%d]: %v", msg.Offset, msg.Topic, msg.Partition, err)
+		if !sleep(ctx, c.retry.Backoff(retry)) {
+			return false
+		}
+	}
+}
+
+func fromKafkaMessage(m kafka.Message) Message {
+	headers := make(map[string]string, len(m.Headers))
+	for _, h := range m.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	return Message{
+		Topic:     m.Topic,
+		Key:       string(m.Key),
+		Value:     m.Value,
+		Headers:   headers,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+	}
+}
+
+// sleep waits for d or until ctx is done, reporting whether the full duration elapsed
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Dead letter sources
+const (
+	DeadLetterSourceProducer = "producer"
+	DeadLetterSourceConsumer = "consumer"
+)
+
+// EventTypeDeadLetter is the event type of messages on the dead-letter topic
+const EventTypeDeadLetter = "dead_letter"
+
+// DeadLetter is the envelope published to the dead-letter topic. It carries
+// everything needed to replay the original message.
+type DeadLetter struct {
+	OriginalTopic string            `json:"originalTopic"`
+	Key           string            `json:"key"`
+	Headers       map[string]string `json:"headers"`
+	Payload       []byte            `json:"payload"`
+	Error         string            `json:"error"`
+	Attempts      int               `json:"attempts"`
+	Source        string            `json:"source"`
+	Partition     int               `json:"partition,omitempty"`
+	Offset        int64             `json:"offset,omitempty"`
+	FailedAt      time.Time         `json:"failedAt"`
+}
+
+// Message rebuilds the original message from the dead letter
+func (d DeadLetter) Message() Message {
+	return Message{
+		Topic:   d.OriginalTopic,
+		Key:     d.Key,
+		Value:   d.Payload,
+		Headers: d.Headers,
+	}
+}
+
+// DecodeDeadLetter parses a message consumed from the dead-letter topic
+func DecodeDeadLetter(msg Message) (DeadLetter, error) {
+	var letter DeadLetter
+	if err := json.Unmarshal(msg.Value, &letter); err != nil {
+		return DeadLetter{}, fmt.Errorf("invalid dead letter: %w", err)
+	}
+	if letter.OriginalTopic == "" {
+		return DeadLetter{}, fmt.Errorf("dead letter has no original topic")
+	}
+	return letter, nil
+}
+
+// PublishDeadLetter sends a message that could not be published or processed to the
+// dead-letter topic, keyed like the original so letters of one order stay in order
+func (p *Producer) PublishDeadLetter(ctx context.Context, msg Message, cause error, attempts int, source string) error {
+	if p.deadLetterTopic == "" {
+		return fmt.Errorf("no dead-letter topic configured")
+	}
+
+	letter := DeadLetter{
+		OriginalTopic: msg.Topic,
+		Key:           msg.Key,
+		Headers:       msg.Headers,
+		Payload:       msg.Value,
+		Error:         cause.Error(),
+		Attempts:      attempts,
+		Source:        source,
+		FailedAt:      time.Now().UTC(),
+	}
+	if source == DeadLetterSourceConsumer {
+		letter.Partition = msg.Partition
+		letter.Offset = msg.Offset
+	}
+
+	value, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	headers := map[string]string{
+		HeaderEventType: EventTypeDeadLetter,
+	}
+	if correlationID := msg.Headers[HeaderCorrelationID]; correlationID != "" {
+		headers[HeaderCorrelationID] = correlationID
+	}
+
+	if err := p.Publish(ctx, Message{
+		Topic:   p.deadLetterTopic,
+		Key:     msg.Key,
+		Value:   value,
+		Headers: headers,
+	}); err != nil {
+		return err
+	}
+
+	log.Printf("☠️ Dead-lettered message for %s after %d attempts: %v", msg.Topic, attempts, cause)
+	return nil
+}
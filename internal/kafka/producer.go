@@ -2,48 +2,232 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-service/internal/config"
+	"order-service/internal/metrics"
+	"order-service/internal/tracing"
+)
+
+// Standard message headers
+const (
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+	HeaderEventID       = "event-id"
+	HeaderCorrelationID = "correlation-id"
+	HeaderProducer      = "producer"
 )
 
+// Message is a Kafka message. Messages with the same Key are written to the
+// same partition, so consumers see them in order.
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+
+	// Partition and Offset are only set on consumed messages
+	Partition int
+	Offset    int64
+}
+
 type Producer struct {
-	writer *kafka.Writer
+	writer          *kafka.Writer
+	serviceName     string
+	deadLetterTopic string
 }
 
-func NewProducer(brokers string) (*Producer, error) {
+func NewProducer(cfg *config.Config) (*Producer, error) {
+	if len(cfg.KafkaBrokers) == 0 {
+		return nil, fmt.Errorf("no kafka brokers configured")
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	compression, err := parseCompression(cfg.KafkaCompression)
+	if err != nil {
+		return nil, err
+	}
+
 	writer := &kafka.Writer{
-		Addr:         kafka.TCP(brokers),
-		Balancer:     &kafka.LeastBytes{},
+		Addr:         kafka.TCP(cfg.KafkaBrokers...),
+		Balancer:     &kafka.Murmur2Balancer{},
 		RequiredAcks: kafka.RequireAll,
-		BatchTimeout: 10 * time.Millisecond,
+		Compression:  compression,
+		BatchSize:    cfg.KafkaBatchSize,
+		BatchTimeout: cfg.KafkaBatchTimeout,
+		Transport:    transport,
 	}
 
-	return &Producer{writer: writer}, nil
+	return &Producer{
+		writer:          writer,
+		serviceName:     cfg.ServiceName,
+		deadLetterTopic: cfg.KafkaTopicDeadLetter,
+	}, nil
+}
+
+// Publish writes an already encoded message. The producer header is always set
+// and an event ID is generated when the caller did not provide one. The write is
+// traced, and its span context replaces any trace headers of msg.
+func (p *Producer) Publish(ctx context.Context, msg Message) (err error) {
+	ctx, span := p.startSpan(ctx, msg)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return p.write(ctx, msg.Topic, p.kafkaMessage(ctx, msg))
+}
+
+// Republish writes a message again as it was first published, e.g. when replaying a
+// dead letter. Its headers are kept as they are, so the original producer, event ID
+// and trace context reach consumers unchanged and redeliveries are still recognised
+// as duplicates. Only a missing producer header is filled in, for messages that
+// never made it to Kafka; no event ID is ever generated.
+func (p *Producer) Republish(ctx context.Context, msg Message) (err error) {
+	ctx, span := p.startSpan(ctx, msg)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return p.write(ctx, msg.Topic, p.republishMessage(msg))
 }
 
-func (p *Producer) Emit(topic string, data interface{}) error {
-	jsonData, err := json.Marshal(data)
+// write sends a single message and records its metrics
+func (p *Producer) write(ctx context.Context, topic string, kafkaMsg kafka.Message) error {
+	start := time.Now()
+	err := p.writer.WriteMessages(ctx, kafkaMsg)
+	p.observe(topic, start, err)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+	return nil
+}
+
+// PublishBatch writes messages in a single call, returning the outcome of each
+// message in order. Headers are set as by Publish, but each message is traced as
+// part of the trace carried in its own headers, falling back to ctx.
+func (p *Producer) PublishBatch(ctx context.Context, msgs []Message) []error {
+	spans := make([]trace.Span, len(msgs))
+	kafkaMsgs := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		var spanCtx context.Context
+		spanCtx, spans[i] = p.startSpan(tracing.Extract(ctx, msg.Headers), msg)
+		kafkaMsgs[i] = p.kafkaMessage(spanCtx, msg)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
+	start := time.Now()
+	err := p.writer.WriteMessages(ctx, kafkaMsgs...)
 
-	err = p.writer.WriteMessages(ctx, kafka.Message{
-		Topic: topic,
-		Value: jsonData,
-	})
+	var writeErrs kafka.WriteErrors
+	if !errors.As(err, &writeErrs) || len(writeErrs) != len(msgs) {
+		// The whole batch failed, or succeeded when err is nil
+		writeErrs = make(kafka.WriteErrors, len(msgs))
+		for i := range writeErrs {
+			writeErrs[i] = err
+		}
+	}
+
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		p.observe(msg.Topic, start, writeErrs[i])
+		if writeErrs[i] != nil {
+			errs[i] = fmt.Errorf("failed to produce message: %w", writeErrs[i])
+		}
+		tracing.EndSpan(spans[i], errs[i])
+	}
+
+	log.Printf("✅ Produced %d of %d messages", len(msgs)-writeErrs.Count(), len(msgs))
+	return errs
+}
+
+// startSpan starts the producer span of a message write
+func (p *Producer) startSpan(ctx context.Context, msg Message) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, msg.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaMessageKey(msg.Key),
+		),
+	)
+}
 
+// kafkaMessage converts msg, carrying the trace context of ctx and the standard
+// producer and event ID headers
+func (p *Producer) kafkaMessage(ctx context.Context, msg Message) kafka.Message {
+	headers := make(map[string]string, len(msg.Headers)+4)
+	for k, v := range msg.Headers {
+		if k != HeaderProducer && v != "" {
+			headers[k] = v
+		}
+	}
+	tracing.Inject(ctx, headers)
+	if headers[HeaderEventID] == "" {
+		headers[HeaderEventID] = uuid.NewString()
+	}
+	headers[HeaderProducer] = p.serviceName
+
+	msg.Headers = headers
+	return toKafkaMessage(msg)
+}
+
+// republishMessage converts msg keeping its headers, only adding the producer header
+// when it is missing
+func (p *Producer) republishMessage(msg Message) kafka.Message {
+	if msg.Headers[HeaderProducer] == "" {
+		headers := make(map[string]string, len(msg.Headers)+1)
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		headers[HeaderProducer] = p.serviceName
+		msg.Headers = headers
+	}
+	return toKafkaMessage(msg)
+}
+
+// toKafkaMessage converts msg as is; it is the inverse of fromKafkaMessage
+func toKafkaMessage(msg Message) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+
+	kafkaMsg := kafka.Message{
+		Topic:   msg.Topic,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+	if msg.Key != "" {
+		kafkaMsg.Key = []byte(msg.Key)
+	}
+	return kafkaMsg
+}
+
+// observe records the duration and outcome of a write started at start
+func (p *Producer) observe(topic string, start time.Time, err error) {
+	metrics.KafkaProduceDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
 	if err != nil {
-		return fmt.Errorf("failed to produce message: %w", err)
+		metrics.KafkaProduceFailures.WithLabelValues(topic).Inc()
 	}
+}
 
-	log.Println("✅ Message produced to", topic)
+// Ping checks that the brokers are reachable with the writer's TLS and SASL settings
+func (p *Producer) Ping(ctx context.Context) error {
+	client := &kafka.Client{
+		Addr:      p.writer.Addr,
+		Transport: p.writer.Transport,
+	}
+	if _, err := client.ApiVersions(ctx, &kafka.ApiVersionsRequest{}); err != nil {
+		return fmt.Errorf("failed to reach kafka brokers: %w", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func headerMap(headers []kafka.Header) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[h.Key] = string(h.Value)
+	}
+	return m
+}
+
+func TestRepublishKeepsHeaders(t *testing.T) {
+	p := &Producer{serviceName: "order-service"}
+	headers := map[string]string{
+		HeaderEventType:     "payment.failed",
+		HeaderCorrelationID: "corr-1",
+		HeaderProducer:      "payment-service",
+		"traceparent":       "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+
+	msg := p.republishMessage(Message{Topic: "payment.failed", Key: "42", Value: []byte("{}"), Headers: headers})
+
+	if got := headerMap(msg.Headers); !reflect.DeepEqual(got, headers) {
+		t.Errorf("republishMessage() headers = %v, want %v", got, headers)
+	}
+	if string(msg.Key) != "42" || msg.Topic != "payment.failed" {
+		t.Errorf("republishMessage() = topic %q key %q, want payment.failed 42", msg.Topic, msg.Key)
+	}
+}
+
+func TestRepublishFillsMissingProducer(t *testing.T) {
+	p := &Producer{serviceName: "order-service"}
+	headers := map[string]string{HeaderEventID: "4d6f3a51-8a3e-4a57-9f59-6a0c1c7d8f10"}
+
+	got := headerMap(p.republishMessage(Message{Topic: "order.created", Headers: headers}).Headers)
+
+	want := map[string]string{
+		HeaderEventID:  "4d6f3a51-8a3e-4a57-9f59-6a0c1c7d8f10",
+		HeaderProducer: "order-service",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("republishMessage() headers = %v, want %v", got, want)
+	}
+	if _, ok := headers[HeaderProducer]; ok {
+		t.Error("republishMessage() modified the caller's headers")
+	}
+}
+
+func TestRepublishNeverGeneratesEventID(t *testing.T) {
+	p := &Producer{serviceName: "order-service"}
+
+	got := headerMap(p.republishMessage(Message{Topic: "payment.succeeded", Headers: map[string]string{}}).Headers)
+
+	if _, ok := got[HeaderEventID]; ok {
+		t.Errorf("republishMessage() added an event ID: %v", got)
+	}
+}
+
+func TestPublishSetsStandardHeaders(t *testing.T) {
+	p := &Producer{serviceName: "order-service"}
+
+	got := headerMap(p.kafkaMessage(context.Background(), Message{
+		Topic: "order.created",
+		Headers: map[string]string{
+			HeaderProducer:  "someone-else",
+			HeaderEventType: "order.created",
+			"empty":         "",
+		},
+	}).Headers)
+
+	if got[HeaderProducer] != "order-service" {
+		t.Errorf("kafkaMessage() producer = %q, want order-service", got[HeaderProducer])
+	}
+	if got[HeaderEventID] == "" {
+		t.Error("kafkaMessage() did not generate an event ID")
+	}
+	if got[HeaderEventType] != "order.created" {
+		t.Errorf("kafkaMessage() event type = %q, want order.created", got[HeaderEventType])
+	}
+	if _, ok := got["empty"]; ok {
+		t.Error("kafkaMessage() kept an empty header")
+	}
+}
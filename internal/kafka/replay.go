@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"order-service/internal/config"
+)
+
+// ReplayOptions controls a dead-letter replay run
+type ReplayOptions struct {
+	// GroupID is the consumer group tracking which dead letters were replayed
+	GroupID string
+	// Limit stops the run after this many letters; zero means no limit
+	Limit int
+	// IdleTimeout stops the run once no letter arrived for this long; zero waits forever
+	IdleTimeout time.Duration
+	// DryRun logs the letters without republishing them or committing offsets
+	DryRun bool
+}
+
+// ReplayDeadLetters republishes dead-lettered messages to their original topics with
+// their original key, headers and payload, committing each letter once it is replayed.
+// It returns the number of letters replayed.
+func ReplayDeadLetters(ctx context.Context, cfg *config.Config, producer *Producer, opts ReplayOptions) (int, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.KafkaBrokers,
+		GroupID:     opts.GroupID,
+		Topic:       cfg.KafkaTopicDeadLetter,
+		Dialer:      dialer,
+		StartOffset: kafka.FirstOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+	})
+	defer reader.Close()
+
+	replayed := 0
+	for opts.Limit == 0 || replayed < opts.Limit {
+		fetchCtx, cancel := ctx, context.CancelFunc(func() {})
+		if opts.IdleTimeout > 0 {
+			fetchCtx, cancel = context.WithTimeout(ctx, opts.IdleTimeout)
+		}
+		m, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				log.Printf("💤 No dead letters for %s, stopping", opts.IdleTimeout)
+				return replayed, nil
+			}
+			return replayed, fmt.Errorf("failed to fetch dead letter: %w", err)
+		}
+
+		letter, err := DecodeDeadLetter(fromKafkaMessage(m))
+		if err != nil {
+			log.Printf("⚠️ Skipping offset %d: %v", m.Offset, err)
+		} else if opts.DryRun {
+			log.Printf("🔎 [dry-run] offset=%d topic=%s key=%s attempts=%d source=%s failedAt=%s error=%q",
+				m.Offset, letter.OriginalTopic, letter.Key, letter.Attempts, letter.Source, letter.FailedAt.Format(time.RFC3339), letter.Error)
+			replayed++
+			continue
+		} else {
+			if err := producer.Republish(ctx, letter.Message()); err != nil {
+				return replayed, fmt.Errorf("failed to replay dead letter at offset %d: %w", m.Offset, err)
+			}
+			log.Printf("🔁 Replayed dead letter at offset %d to %s", m.Offset, letter.OriginalTopic)
+			replayed++
+		}
+
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			return replayed, fmt.Errorf("failed to commit dead letter at offset %d: %w", m.Offset, err)
+		}
+	}
+
+	return replayed, nil
+}
@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"time"
+)
+
+// RetryPolicy describes how many times an operation is attempted and how long to
+// wait between attempts. The delay doubles after every attempt up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff returns the delay to wait after the given (1-based) failed attempt
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// Exhausted reports whether no attempts are left after the given attempt
+func (p RetryPolicy) Exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 100, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffCappedInitial(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Minute, MaxBackoff: 30 * time.Second}
+
+	if got := policy.Backoff(1); got != 30*time.Second {
+		t.Errorf("Backoff(1) = %s, want the 30s maximum", got)
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	tests := []struct {
+		maxAttempts int
+		attempt     int
+		want        bool
+	}{
+		{maxAttempts: 3, attempt: 1, want: false},
+		{maxAttempts: 3, attempt: 2, want: false},
+		{maxAttempts: 3, attempt: 3, want: true},
+		{maxAttempts: 3, attempt: 4, want: true},
+		{maxAttempts: 0, attempt: 1000, want: false},
+	}
+
+	for _, tt := range tests {
+		policy := RetryPolicy{MaxAttempts: tt.maxAttempts}
+		if got := policy.Exhausted(tt.attempt); got != tt.want {
+			t.Errorf("RetryPolicy{MaxAttempts: %d}.Exhausted(%d) = %v, want %v", tt.maxAttempts, tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"order-service/internal/config"
+)
+
+// newTLSConfig builds the client TLS configuration, or returns nil when TLS is disabled
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if !cfg.KafkaTLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.KafkaTLSInsecureSkipVerify,
+	}
+
+	if cfg.KafkaTLSCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.KafkaTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in kafka CA file %s", cfg.KafkaTLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.KafkaTLSCertFile != "" || cfg.KafkaTLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.KafkaTLSCertFile, cfg.KafkaTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newSASLMechanism returns the configured SASL mechanism, or nil when SASL is disabled
+func newSASLMechanism(cfg *config.Config) (sasl.Mechanism, error) {
+	switch strings.ToUpper(cfg.KafkaSASLMechanism) {
+	case "":
+		return nil, nil
+	case "PLAIN":
+		return plain.Mechanism{Username: cfg.KafkaSASLUsername, Password: cfg.KafkaSASLPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.KafkaSASLUsername, cfg.KafkaSASLPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.KafkaSASLUsername, cfg.KafkaSASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism: %s", cfg.KafkaSASLMechanism)
+	}
+}
+
+// parseCompression maps a codec name to a kafka-go compression codec. Zero means no compression.
+func parseCompression(name string) (kafka.Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported kafka compression: %s", name)
+	}
+}
+
+// newTransport builds the transport shared by writers, carrying TLS and SASL settings
+func newTransport(cfg *config.Config) (*kafka.Transport, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, err := newSASLMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Transport{
+		TLS:  tlsConfig,
+		SASL: mechanism,
+	}, nil
+}
+
+// newDialer builds the dialer used by readers, carrying TLS and SASL settings
+func newDialer(cfg *config.Config) (*kafka.Dialer, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, err := newSASLMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           tlsConfig,
+		SASLMechanism: mechanism,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so no database is needed until a connection is acquired
+	pool, err := pgxpool.New(context.Background(), "postgres://user@127.0.0.1:1/orders?pool_max_conns=4")
+	if err != nil {
+		t.Fatalf("pgxpool.New() error = %v", err)
+	}
+	defer pool.Close()
+
+	collector := NewPoolCollector(pool)
+
+	if got := testutil.CollectAndCount(collector); got != 8 {
+		t.Errorf("CollectAndCount() = %d, want 8", got)
+	}
+
+	expected := `
+# HELP order_service_db_pool_acquired_connections Connections currently checked out of the pool.
+# TYPE order_service_db_pool_acquired_connections gauge
+order_service_db_pool_acquired_connections 0
+# HELP order_service_db_pool_acquires_total Successful connection acquires.
+# TYPE order_service_db_pool_acquires_total counter
+order_service_db_pool_acquires_total 0
+# HELP order_service_db_pool_max_connections Maximum size of the pool.
+# TYPE order_service_db_pool_max_connections gauge
+order_service_db_pool_max_connections 4
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"order_service_db_pool_acquired_connections",
+		"order_service_db_pool_acquires_total",
+		"order_service_db_pool_max_connections",
+	); err != nil {
+		t.Errorf("CollectAndCompare() error = %v", err)
+	}
+}
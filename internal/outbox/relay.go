@@ -0,0 +1,195 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/config"
+	"order-service/internal/database"
+	"order-service/internal/database/db"
+	"order-service/internal/kafka"
+)
+
+// Outbox statuses
+const (
+	StatusPending      = "PENDING"
+	StatusSent         = "SENT"
+	StatusDeadLettered = "DEAD_LETTERED"
+)
+
+const publishTimeout = 15 * time.Second
+
+// claimLease is how long claimed events are reserved for a relay. It outlasts the
+// publish and dead-letter timeouts, so a lease only expires if the relay died.
+const claimLease = 4 * publishTimeout
+
+// Relay publishes pending outbox events to Kafka and marks them sent
+type Relay struct {
+	db        *database.DB
+	producer  *kafka.Producer
+	interval  time.Duration
+	batchSize int32
+	retry     kafka.RetryPolicy
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRelay(db *database.DB, producer *kafka.Producer, cfg *config.Config) *Relay {
+	return &Relay{
+		db:        db,
+		producer:  producer,
+		interval:  cfg.OutboxPollInterval,
+		batchSize: int32(cfg.OutboxBatchSize),
+		retry: kafka.RetryPolicy{
+			MaxAttempts:    cfg.OutboxMaxAttempts,
+			InitialBackoff: cfg.OutboxRetryInitialBackoff,
+			MaxBackoff:     cfg.OutboxRetryMaxBackoff,
+		},
+	}
+}
+
+// Start runs the relay loop in a background goroutine until Stop is called
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+
+	log.Printf("🔁 Outbox relay started (interval=%s, batch=%d)", r.interval, r.batchSize)
+}
+
+// Stop signals the relay loop to exit and waits for the current batch to finish
+func (r *Relay) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	log.Println("✅ Outbox relay stopped")
+}
+
+func (r *Relay) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep draining while full batches come back. A batch in progress
+			// is allowed to finish even if the relay is being stopped.
+			for {
+				n, err := r.publishBatch(context.WithoutCancel(ctx))
+				if err != nil {
+					log.Printf("❌ Outbox relay batch failed: %v", err)
+					break
+				}
+				if n < int(r.batchSize) || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// publishBatch leases a batch of due events, publishes them in one write and records
+// the outcome of each. No transaction or row lock is held while publishing; the
+// lease keeps other relays off the events until the outcome is recorded.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	events, err := r.db.Queries.ClaimPendingOutboxEvents(ctx, db.ClaimPendingOutboxEventsParams{
+		LockedUntil: pgtype.Timestamp{Time: time.Now().UTC().Add(claimLease), Valid: true},
+		Limit:       r.batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	// The claim returns at most one event per order, so the batch can be published
+	// in one write without reordering events of an order
+	msgs := make([]kafka.Message, len(events))
+	for i, event := range events {
+		msgs[i] = toMessage(event)
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	publishErrs := r.producer.PublishBatch(publishCtx, msgs)
+	cancel()
+
+	for i, event := range events {
+		if publishErrs[i] == nil {
+			if err := r.db.Queries.MarkOutboxEventSent(ctx, event.ID); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := r.recordFailure(ctx, event, msgs[i], publishErrs[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// recordFailure schedules a failed event for another attempt, or dead-letters it once
+// its attempts are exhausted
+func (r *Relay) recordFailure(ctx context.Context, event db.Outbox, msg kafka.Message, publishErr error) error {
+	attempts := int(event.Attempts) + 1
+	log.Printf("⚠️ Failed to publish outbox event %d to %s (attempt %d): %v", event.ID, event.Topic, attempts, publishErr)
+
+	status := StatusPending
+	nextAttempt := r.retry.Backoff(attempts)
+
+	if r.retry.Exhausted(attempts) {
+		dlqCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		dlqErr := r.producer.PublishDeadLetter(dlqCtx, msg, publishErr, attempts, kafka.DeadLetterSourceProducer)
+		cancel()
+
+		if dlqErr == nil {
+			status = StatusDeadLettered
+			nextAttempt = 0
+		} else {
+			// Keep the event pending rather than lose it
+			log.Printf("❌ Failed to dead-letter outbox event %d: %v", event.ID, dlqErr)
+			nextAttempt = r.retry.MaxBackoff
+		}
+	}
+
+	return r.db.Queries.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		ID:            event.ID,
+		Status:        status,
+		LastError:     pgtype.Text{String: publishErr.Error(), Valid: true},
+		NextAttemptAt: pgtype.Timestamp{Time: time.Now().UTC().Add(nextAttempt), Valid: true},
+	})
+}
+
+// toMessage builds the Kafka message for an outbox event
+func toMessage(event db.Outbox) kafka.Message {
+	headers := make(map[string]string)
+	if len(event.Headers) > 0 {
+		if err := json.Unmarshal(event.Headers, &headers); err != nil {
+			log.Printf("⚠️ Ignoring invalid headers on outbox event %d: %v", event.ID, err)
+		}
+	}
+	if event.EventID.Valid {
+		headers[kafka.HeaderEventID] = event.EventID.String()
+	}
+
+	return kafka.Message{
+		Topic:   event.Topic,
+		Key:     event.MessageKey,
+		Value:   event.Payload,
+		Headers: headers,
+	}
+}
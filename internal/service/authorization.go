@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"order-service/internal/auth"
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// authorizeUser allows the caller to act on userId's orders. Calls without a
+// principal come from inside the service (consumers, jobs) or from a server
+// running without authentication, and are allowed.
+func authorizeUser(ctx context.Context, userId int32) error {
+	principal := auth.FromContext(ctx)
+	if principal == nil || principal.IsPrivileged() {
+		return nil
+	}
+	if principal.HasRole(auth.RoleCustomer) && principal.UserID == userId {
+		return nil
+	}
+	log.Printf("🚫 Access denied: Subject=%s, UserID=%d", principal.Subject, userId)
+	return errors.ErrForbidden
+}
+
+// authorizeOrder allows the caller to access order. Customers get not found for
+// other users' orders so order IDs cannot be probed.
+func authorizeOrder(ctx context.Context, order *db.Order) error {
+	if err := authorizeUser(ctx, order.UserID); err != nil {
+		return errors.ErrOrderNotFound
+	}
+	return nil
+}
+
+// authorizePrivileged restricts back-office operations to admin and service callers
+func authorizePrivileged(ctx context.Context) error {
+	principal := auth.FromContext(ctx)
+	if principal == nil || principal.IsPrivileged() {
+		return nil
+	}
+	log.Printf("🚫 Access denied: Subject=%s requires admin or service role", principal.Subject)
+	return errors.ErrForbidden
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database"
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// MaxIdempotencyKeyLength matches the idempotency_keys.idempotency_key column size
+const MaxIdempotencyKeyLength = 255
+
+// hashCreateOrderRequest returns a stable fingerprint of a CreateOrder request,
+// used to detect an idempotency key being reused with a different payload. It hashes
+// the request after normalization: the resolved currency code, unit prices and client
+// total in minor units, so a retry spelling the currency or the amounts differently
+// still matches. clientTotal is nil when the request carried no usable total.
+func hashCreateOrderRequest(userID int32, currency string, items []OrderItemParams, prices []Money, clientTotal *Money) (string, error) {
+	type line struct {
+		ProductID  int32 `json:"productId"`
+		Quantity   int32 `json:"quantity"`
+		PriceMinor int64 `json:"priceMinor"`
+	}
+	lines := make([]line, len(items))
+	for i, item := range items {
+		lines[i] = line{ProductID: item.ProductID, Quantity: item.Quantity, PriceMinor: prices[i].Amount}
+	}
+
+	var totalMinor *int64
+	if clientTotal != nil {
+		totalMinor = &clientTotal.Amount
+	}
+
+	payload, err := json.Marshal(struct {
+		UserID           int32  `json:"userId"`
+		Currency         string `json:"currency"`
+		TotalAmountMinor *int64 `json:"totalAmountMinor"`
+		Products         []line `json:"products"`
+	}{
+		UserID:           userID,
+		Currency:         currency,
+		TotalAmountMinor: totalMinor,
+		Products:         lines,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkIdempotencyReplay decides whether a request hashing to requestHash may replay
+// the live key existing. A key reused with a different payload is rejected, and so
+// is a key whose original request has not stored its order yet, e.g. because it is
+// still in flight or was rolled back.
+func checkIdempotencyReplay(existing db.IdempotencyKey, requestHash string) error {
+	if existing.RequestHash != requestHash {
+		return errors.ErrIdempotencyReused
+	}
+	if !existing.OrderID.Valid {
+		return errors.NewOrderError(errors.CodeOrderCreateFailed, "original request for this idempotency key did not complete")
+	}
+	return nil
+}
+
+// reserveIdempotencyKey claims the key within the caller's transaction.
+// If the key was already used for the same request it returns the order created
+// by the original call; a key reused with a different payload is rejected.
+func (s *OrderService) reserveIdempotencyKey(ctx context.Context, qtx *db.Queries, params CreateOrderParams, requestHash string) (*db.IdempotencyKey, *db.Order, []db.OrderProduct, error) {
+	key, err := qtx.ReserveIdempotencyKey(ctx, db.ReserveIdempotencyKeyParams{
+		UserID:         params.UserID,
+		IdempotencyKey: params.IdempotencyKey,
+		RequestHash:    requestHash,
+		ExpiresAt:      pgtype.Timestamp{Time: time.Now().UTC().Add(s.cfg.IdempotencyKeyTTL), Valid: true},
+	})
+	if err == nil {
+		return &key, nil, nil, nil
+	}
+	if !stderrors.Is(err, pgx.ErrNoRows) {
+		log.Printf("❌ Failed to reserve idempotency key: %v", err)
+		return nil, nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	// A live key already exists, replay the original result
+	existing, err := qtx.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		UserID:         params.UserID,
+		IdempotencyKey: params.IdempotencyKey,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to get idempotency key: %v", err)
+		return nil, nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if err := checkIdempotencyReplay(existing, requestHash); err != nil {
+		return nil, nil, nil, err
+	}
+
+	order, err := qtx.GetOrderByID(ctx, existing.OrderID.Int32)
+	if err != nil {
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	products, err := qtx.GetOrderProductsByOrderID(ctx, order.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get order products: %v", err)
+		return nil, nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	log.Printf("🔁 Replayed CreateOrder for idempotency key: OrderID=%d, UserID=%d", order.ID, order.UserID)
+	return nil, &order, products, nil
+}
+
+// NewIdempotencyJanitor returns a janitor that deletes expired idempotency keys
+func NewIdempotencyJanitor(db *database.DB, interval time.Duration) *Janitor {
+	return &Janitor{
+		description: "expired idempotency keys",
+		interval:    interval,
+		purge:       db.Queries.DeleteExpiredIdempotencyKeys,
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// requestHash normalizes a CreateOrder request the way CreateOrder does and hashes it
+func requestHash(t *testing.T, params CreateOrderParams) string {
+	t.Helper()
+
+	currency := params.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		t.Fatalf("NormalizeCurrency(%q) error = %v", params.Currency, err)
+	}
+	prices, _, err := priceOrderItems(params.Products, currency)
+	if err != nil {
+		t.Fatalf("priceOrderItems() error = %v", err)
+	}
+	total, _, err := clientOrderTotal(params, currency)
+	if err != nil {
+		t.Fatalf("clientOrderTotal() error = %v", err)
+	}
+
+	hash, err := hashCreateOrderRequest(params.UserID, currency, params.Products, prices, &total)
+	if err != nil {
+		t.Fatalf("hashCreateOrderRequest() error = %v", err)
+	}
+	return hash
+}
+
+func TestHashCreateOrderRequest(t *testing.T) {
+	price := int64(1999)
+	total := int64(3998)
+	otherTotal := int64(3999)
+	base := CreateOrderParams{
+		UserID:           1,
+		Currency:         "USD",
+		TotalAmountMinor: &total,
+		Products:         []OrderItemParams{{ProductID: 7, Quantity: 2, PriceMinor: &price}},
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(p *CreateOrderParams)
+		wantMatch bool
+	}{
+		{name: "identical", modify: func(p *CreateOrderParams) {}, wantMatch: true},
+		{name: "lower-case currency", modify: func(p *CreateOrderParams) { p.Currency = "usd" }, wantMatch: true},
+		{name: "default currency", modify: func(p *CreateOrderParams) { p.Currency = "" }, wantMatch: true},
+		{
+			name: "decimal amounts",
+			modify: func(p *CreateOrderParams) {
+				p.TotalAmountMinor = nil
+				p.TotalAmount = 39.98
+				p.Products = []OrderItemParams{{ProductID: 7, Quantity: 2, Price: 19.99}}
+			},
+			wantMatch: true,
+		},
+		{name: "different minor total", modify: func(p *CreateOrderParams) { p.TotalAmountMinor = &otherTotal }, wantMatch: false},
+		{name: "different currency", modify: func(p *CreateOrderParams) { p.Currency = "EUR" }, wantMatch: false},
+		{name: "different user", modify: func(p *CreateOrderParams) { p.UserID = 2 }, wantMatch: false},
+		{
+			name: "different quantity",
+			modify: func(p *CreateOrderParams) {
+				p.Products = []OrderItemParams{{ProductID: 7, Quantity: 3, PriceMinor: &price}}
+			},
+			wantMatch: false,
+		},
+	}
+
+	want := requestHash(t, base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := base
+			tt.modify(&params)
+
+			if got := requestHash(t, params); (got == want) != tt.wantMatch {
+				t.Errorf("hash match = %v, want %v", got == want, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestCheckIdempotencyReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing db.IdempotencyKey
+		hash     string
+		wantCode string
+	}{
+		{
+			name:     "replay",
+			existing: db.IdempotencyKey{RequestHash: "abc", OrderID: pgtype.Int4{Int32: 42, Valid: true}},
+			hash:     "abc",
+		},
+		{
+			name:     "different payload",
+			existing: db.IdempotencyKey{RequestHash: "abc", OrderID: pgtype.Int4{Int32: 42, Valid: true}},
+			hash:     "def",
+			wantCode: errors.CodeIdempotencyReused,
+		},
+		{
+			name:     "concurrent reservation still in flight",
+			existing: db.IdempotencyKey{RequestHash: "abc"},
+			hash:     "abc",
+			wantCode: errors.CodeOrderCreateFailed,
+		},
+		{
+			name:     "concurrent reservation with a different payload",
+			existing: db.IdempotencyKey{RequestHash: "abc"},
+			hash:     "def",
+			wantCode: errors.CodeIdempotencyReused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkIdempotencyReplay(tt.existing, tt.hash)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("checkIdempotencyReplay() error = %v, want a replay", err)
+				}
+				return
+			}
+			if errors.GetErrorCode(err) != tt.wantCode {
+				t.Errorf("checkIdempotencyReplay() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Janitor periodically deletes rows that are no longer needed
+type Janitor struct {
+	// description names the purged rows in log messages
+	description string
+	interval    time.Duration
+	purge       func(ctx context.Context) (int64, error)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start runs the janitor in a background goroutine until Stop is called
+func (j *Janitor) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := j.purge(ctx)
+				if err != nil {
+					log.Printf("❌ Failed to purge %s: %v", j.description, err)
+					continue
+				}
+				if deleted > 0 {
+					log.Printf("🧹 Purged %d %s", deleted, j.description)
+				}
+			}
+		}
+	}()
+}
+
+// Stop signals the janitor to exit and waits for it
+func (j *Janitor) Stop() {
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.wg.Wait()
+}
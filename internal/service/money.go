@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"order-service/internal/errors"
+)
+
+// currencyExponents maps every active ISO 4217 currency code to the number of
+// decimal places of its minor unit. Precious metals, testing and fund codes
+// without a minor unit (XAU, XTS, XDR, ...) are not accepted.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0,
+	"XPF": 0,
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2,
+	"AZN": 2, "BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BMD": 2, "BND": 2, "BOB": 2,
+	"BOV": 2, "BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2,
+	"CDF": 2, "CHE": 2, "CHF": 2, "CHW": 2, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2,
+	"CUP": 2, "CVE": 2, "CZK": 2, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2,
+	"ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2,
+	"GMD": 2, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "IRR": 2, "JMD": 2, "KES": 2, "KGS": 2, "KHR": 2, "KPW": 2,
+	"KYD": 2, "KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "MAD": 2,
+	"MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2,
+	"MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2,
+	"NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2,
+	"PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SBD": 2,
+	"SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2,
+	"SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2,
+	"TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "USD": 2, "USN": 2,
+	"UYU": 2, "UZS": 2, "VED": 2, "VES": 2, "WST": 2, "XCD": 2, "XCG": 2, "YER": 2,
+	"ZAR": 2, "ZMW": 2, "ZWG": 2,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+const defaultCurrencyExponent = 2
+
+// Money is an amount in the minor units (e.g. cents) of a currency
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// NormalizeCurrency upper-cases a currency code and checks it is an active ISO 4217 code
+func NormalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := currencyExponents[code]; !ok {
+		return "", errors.NewFieldError(errors.CodeInvalidInput, "currency", "invalid currency: "+currency)
+	}
+	return code, nil
+}
+
+// CurrencyExponent returns the number of decimal places of a currency's minor unit.
+// Unknown codes, which NormalizeCurrency rejects, are assumed to use cents.
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return defaultCurrencyExponent
+}
+
+// MoneyFromFloat converts a decimal major-unit amount into minor units, rounding half away from zero
+func MoneyFromFloat(value float64, currency string) (Money, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Money{}, errors.NewOrderError(errors.CodeInvalidInput, "amount must be a finite number")
+	}
+
+	scaled := math.Round(value * math.Pow10(CurrencyExponent(currency)))
+	if scaled >= math.MaxInt64 || scaled < math.MinInt64 {
+		return Money{}, errors.NewOrderError(errors.CodeInvalidInput, "amount is out of range")
+	}
+
+	return Money{Amount: int64(scaled), Currency: currency}, nil
+}
+
+// Float64 returns the amount in major units. Only use it for display and legacy fields.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / math.Pow10(CurrencyExponent(m.Currency))
+}
+
+// Add returns m + o, failing on currency mismatch or overflow
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, errors.NewOrderError(errors.CodeInvalidInput, fmt.Sprintf("currency mismatch: %s and %s", m.Currency, o.Currency))
+	}
+
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, errors.NewOrderError(errors.CodeInvalidInput, "amount is out of range")
+	}
+
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Mul returns m * n, failing on overflow
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Amount: 0, Currency: m.Currency}, nil
+	}
+
+	product := m.Amount * n
+	if product/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, errors.NewOrderError(errors.CodeInvalidInput, "amount is out of range")
+	}
+
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+func (m Money) String() string {
+	exp := CurrencyExponent(m.Currency)
+	return fmt.Sprintf("%.*f %s", exp, m.Float64(), m.Currency)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "USD", want: "USD"},
+		{in: " eur ", want: "EUR"},
+		{in: "jpy", want: "JPY"},
+		{in: "KWD", want: "KWD"},
+		{in: "", wantErr: true},
+		{in: "US", wantErr: true},
+		{in: "USDT", wantErr: true},
+		{in: "AAA", wantErr: true},
+		{in: "XAU", wantErr: true},
+		{in: "HRK", wantErr: true},
+		{in: "U$D", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeCurrency(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeCurrency(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeCurrency(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestCurrencyExponent(t *testing.T) {
+	tests := map[string]int{
+		"USD": 2,
+		"EUR": 2,
+		"JPY": 0,
+		"KRW": 0,
+		"BHD": 3,
+		"KWD": 3,
+		"CLF": 4,
+		"ZZZ": defaultCurrencyExponent,
+	}
+
+	for currency, want := range tests {
+		if got := CurrencyExponent(currency); got != want {
+			t.Errorf("CurrencyExponent(%q) = %d, want %d", currency, got, want)
+		}
+	}
+}
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{name: "cents", value: 12.34, currency: "USD", want: 1234},
+		{name: "half rounds up", value: 0.125, currency: "USD", want: 13},
+		{name: "negative half rounds away from zero", value: -0.125, currency: "USD", want: -13},
+		{name: "below half rounds down", value: 0.124, currency: "USD", want: 12},
+		{name: "binary representation below half", value: 1.005, currency: "USD", want: 100},
+		{name: "zero exponent", value: 1500, currency: "JPY", want: 1500},
+		{name: "zero exponent rounds", value: 1500.5, currency: "JPY", want: 1501},
+		{name: "three digit exponent", value: 1.234, currency: "KWD", want: 1234},
+		{name: "three digit exponent rounds", value: 12.3456, currency: "BHD", want: 12346},
+		{name: "zero", value: 0, currency: "USD", want: 0},
+		{name: "NaN", value: math.NaN(), currency: "USD", wantErr: true},
+		{name: "infinity", value: math.Inf(1), currency: "USD", wantErr: true},
+		{name: "overflow", value: 1e17, currency: "USD", wantErr: true},
+		{name: "negative overflow", value: -1e17, currency: "USD", wantErr: true},
+		{name: "overflow with three digit exponent", value: 1e16, currency: "KWD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyFromFloat(tt.value, tt.currency)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MoneyFromFloat(%v, %s) = %v, want an error", tt.value, tt.currency, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MoneyFromFloat(%v, %s) error = %v", tt.value, tt.currency, err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.currency {
+				t.Errorf("MoneyFromFloat(%v, %s) = %d %s, want %d %s", tt.value, tt.currency, got.Amount, got.Currency, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    int64
+		wantErr bool
+	}{
+		{name: "sum", a: NewMoney(150, "USD"), b: NewMoney(250, "USD"), want: 400},
+		{name: "negative", a: NewMoney(150, "USD"), b: NewMoney(-200, "USD"), want: -50},
+		{name: "zero exponent", a: NewMoney(1000, "JPY"), b: NewMoney(1, "JPY"), want: 1001},
+		{name: "currency mismatch", a: NewMoney(1, "USD"), b: NewMoney(1, "EUR"), wantErr: true},
+		{name: "overflow", a: NewMoney(math.MaxInt64, "USD"), b: NewMoney(1, "USD"), wantErr: true},
+		{name: "negative overflow", a: NewMoney(math.MinInt64, "USD"), b: NewMoney(-1, "USD"), wantErr: true},
+		{name: "max", a: NewMoney(math.MaxInt64-1, "USD"), b: NewMoney(1, "USD"), want: math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("%v.Add(%v) = %v, want an error", tt.a, tt.b, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v.Add(%v) error = %v", tt.a, tt.b, err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.a.Currency {
+				t.Errorf("%v.Add(%v) = %d %s, want %d %s", tt.a, tt.b, got.Amount, got.Currency, tt.want, tt.a.Currency)
+			}
+		})
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		n       int64
+		want    int64
+		wantErr bool
+	}{
+		{name: "product", m: NewMoney(1999, "USD"), n: 3, want: 5997},
+		{name: "by zero", m: NewMoney(1999, "USD"), n: 0, want: 0},
+		{name: "zero amount", m: NewMoney(0, "USD"), n: math.MaxInt64, want: 0},
+		{name: "negative", m: NewMoney(-5, "KWD"), n: 4, want: -20},
+		{name: "overflow", m: NewMoney(math.MaxInt64/2+1, "USD"), n: 2, wantErr: true},
+		{name: "large overflow", m: NewMoney(1<<40, "USD"), n: 1 << 40, wantErr: true},
+		{name: "min by minus one", m: NewMoney(math.MinInt64, "USD"), n: -1, wantErr: true},
+		{name: "minus one by min", m: NewMoney(-1, "USD"), n: math.MinInt64, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Mul(tt.n)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("%v.Mul(%d) = %v, want an error", tt.m, tt.n, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v.Mul(%d) error = %v", tt.m, tt.n, err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.m.Currency {
+				t.Errorf("%v.Mul(%d) = %d %s, want %d %s", tt.m, tt.n, got.Amount, got.Currency, tt.want, tt.m.Currency)
+			}
+		})
+	}
+}
+
+func TestPriceOrderItemsPrefersMinorUnits(t *testing.T) {
+	priceMinor := int64(1999)
+	items := []OrderItemParams{
+		{ProductID: 1, Quantity: 2, PriceMinor: &priceMinor, Price: 1},
+		{ProductID: 2, Quantity: 1, Price: 0.5},
+	}
+
+	prices, total, err := priceOrderItems(items, "USD")
+	if err != nil {
+		t.Fatalf("priceOrderItems() error = %v", err)
+	}
+	if prices[0].Amount != 1999 || prices[1].Amount != 50 {
+		t.Errorf("priceOrderItems() prices = %v, want [1999 50]", prices)
+	}
+	if total.Amount != 4048 {
+		t.Errorf("priceOrderItems() total = %d, want 4048", total.Amount)
+	}
+
+	zero := int64(0)
+	if _, _, err := priceOrderItems([]OrderItemParams{{ProductID: 1, Quantity: 1, PriceMinor: &zero, Price: 10}}, "USD"); err == nil {
+		t.Error("priceOrderItems() with a zero price_minor: expected an error")
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// Cancellation reason codes
+const (
+	CancelReasonCustomerRequest = "CUSTOMER_REQUEST"
+	CancelReasonPaymentFailed   = "PAYMENT_FAILED"
+	CancelReasonOutOfStock      = "OUT_OF_STOCK"
+	CancelReasonFraudSuspected  = "FRAUD_SUSPECTED"
+	CancelReasonDuplicateOrder  = "DUPLICATE_ORDER"
+	CancelReasonOther           = "OTHER"
+)
+
+var cancelReasons = map[string]bool{
+	CancelReasonCustomerRequest: true,
+	CancelReasonPaymentFailed:   true,
+	CancelReasonOutOfStock:      true,
+	CancelReasonFraudSuspected:  true,
+	CancelReasonDuplicateOrder:  true,
+	CancelReasonOther:           true,
+}
+
+// MaxCancelNoteLength bounds the free-text note stored with a cancellation
+const MaxCancelNoteLength = 1000
+
+// IsValidCancelReason reports whether reason is a known cancellation reason code
+func IsValidCancelReason(reason string) bool {
+	return cancelReasons[reason]
+}
+
+// IsCancellable reports whether an order in status may be cancelled
+func IsCancellable(status string) bool {
+	return CanTransition(status, StatusCancelled)
+}
+
+type CancelOrderParams struct {
+	OrderID    int32
+	ReasonCode string
+	// Note is optional free text, required when ReasonCode is OTHER
+	Note   string
+	Actor  string
+	Source string
+	// ExpectedVersion, when set, must match the order's current version
+	ExpectedVersion int32
+}
+
+// validateCancelOrderParams checks a cancellation request before the order is loaded
+func validateCancelOrderParams(params CancelOrderParams) error {
+	if params.OrderID <= 0 {
+		return errors.NewFieldError(errors.CodeInvalidInput, "id", "order ID is required")
+	}
+	if !IsValidCancelReason(params.ReasonCode) {
+		return errors.NewFieldError(errors.CodeInvalidInput, "reason_code", "invalid cancellation reason: "+params.ReasonCode)
+	}
+	if params.ReasonCode == CancelReasonOther && params.Note == "" {
+		return errors.NewFieldError(errors.CodeInvalidInput, "note", "a note is required when the reason is OTHER")
+	}
+	if len(params.Note) > MaxCancelNoteLength {
+		return errors.NewFieldError(errors.CodeInvalidInput, "note", "note is too long")
+	}
+	if params.ExpectedVersion < 0 {
+		return errors.NewFieldError(errors.CodeInvalidInput, "expected_version", "expected version cannot be negative")
+	}
+	return nil
+}
+
+func (s *OrderService) CancelOrder(ctx context.Context, params CancelOrderParams) (*db.Order, error) {
+	if err := validateCancelOrderParams(params); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to begin transaction: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
+
+	order, err := s.cancelOrder(ctx, qtx, params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("❌ Failed to commit transaction: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	return order, nil
+}
+
+// cancelNote is the cancellation note used when an order is cancelled through
+// UpdateOrderStatus, which has no reason code
+func cancelNote(reason string) string {
+	if reason != "" {
+		return reason
+	}
+	return "cancelled via UpdateOrderStatus"
+}
+
+// cancelOrder cancels an order within the caller's transaction and records why.
+// The order.cancelled event carries the reason and the order items so downstream
+// services can release stock. extra is merged into that event.
+func (s *OrderService) cancelOrder(ctx context.Context, qtx *db.Queries, params CancelOrderParams, extra map[string]interface{}) (*db.Order, error) {
+	current, err := qtx.GetOrderByIDForUpdate(ctx, params.OrderID)
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.ErrOrderNotFound
+		}
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if err := authorizeOrder(ctx, &current); err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(&current, params.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	if !IsCancellable(current.Status) {
+		return nil, errors.NewOrderError(
+			errors.CodeInvalidStatus,
+			fmt.Sprintf("order in status %s cannot be cancelled", current.Status),
+		)
+	}
+
+	products, err := qtx.GetOrderProductsByOrderID(ctx, current.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get order products: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	details := map[string]interface{}{
+		"reasonCode": params.ReasonCode,
+		"note":       params.Note,
+		"items":      s.mapProductsToItems(products, current.Currency),
+	}
+	for k, v := range extra {
+		details[k] = v
+	}
+
+	reason := params.ReasonCode
+	if params.Note != "" {
+		reason += ": " + params.Note
+	}
+
+	order, err := s.transitionStatus(ctx, qtx, current.ID, StatusCancelled, params.ExpectedVersion, StatusChange{
+		Actor:  params.Actor,
+		Source: params.Source,
+		Reason: reason,
+	}, details)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := qtx.CreateOrderCancellation(ctx, db.CreateOrderCancellationParams{
+		OrderID:        order.ID,
+		PreviousStatus: current.Status,
+		ReasonCode:     params.ReasonCode,
+		Note:           pgtype.Text{String: params.Note, Valid: params.Note != ""},
+		Actor:          params.Actor,
+	}); err != nil {
+		log.Printf("❌ Failed to record order cancellation: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	log.Printf("🚫 Order cancelled: ID=%d, Reason=%s, Actor=%s", order.ID, params.ReasonCode, params.Actor)
+	return order, nil
+}
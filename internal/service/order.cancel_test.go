@@ -0,0 +1,98 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"order-service/internal/errors"
+)
+
+func TestIsCancellable(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{status: StatusPending, want: true},
+		{status: StatusConfirmed, want: true},
+		{status: StatusProcessing, want: true},
+		{status: StatusShipped, want: false},
+		{status: StatusDelivered, want: false},
+		{status: StatusCancelled, want: false},
+		{status: StatusRefunded, want: false},
+		{status: "UNKNOWN", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCancellable(tt.status); got != tt.want {
+			t.Errorf("IsCancellable(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCancelOrderParams(t *testing.T) {
+	valid := CancelOrderParams{OrderID: 1, ReasonCode: CancelReasonCustomerRequest}
+
+	tests := []struct {
+		name      string
+		params    CancelOrderParams
+		wantField string
+	}{
+		{name: "valid", params: valid},
+		{
+			name:   "other with a note",
+			params: CancelOrderParams{OrderID: 1, ReasonCode: CancelReasonOther, Note: "changed my mind"},
+		},
+		{
+			name:      "missing order",
+			params:    CancelOrderParams{ReasonCode: CancelReasonCustomerRequest},
+			wantField: "id",
+		},
+		{
+			name:      "unknown reason",
+			params:    CancelOrderParams{OrderID: 1, ReasonCode: "BORED"},
+			wantField: "reason_code",
+		},
+		{
+			name:      "missing reason",
+			params:    CancelOrderParams{OrderID: 1},
+			wantField: "reason_code",
+		},
+		{
+			name:      "other without a note",
+			params:    CancelOrderParams{OrderID: 1, ReasonCode: CancelReasonOther},
+			wantField: "note",
+		},
+		{
+			name:      "note too long",
+			params:    CancelOrderParams{OrderID: 1, ReasonCode: CancelReasonOther, Note: strings.Repeat("x", MaxCancelNoteLength+1)},
+			wantField: "note",
+		},
+		{
+			name:      "negative expected version",
+			params:    CancelOrderParams{OrderID: 1, ReasonCode: CancelReasonCustomerRequest, ExpectedVersion: -1},
+			wantField: "expected_version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCancelOrderParams(tt.params)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("validateCancelOrderParams() error = %v", err)
+				}
+				return
+			}
+			assertFieldError(t, err, errors.CodeInvalidInput, tt.wantField)
+		})
+	}
+}
+
+func TestCancelNote(t *testing.T) {
+	if got := cancelNote("customer called"); got != "customer called" {
+		t.Errorf("cancelNote() = %q, want the given reason", got)
+	}
+	if got := cancelNote(""); got != "cancelled via UpdateOrderStatus" {
+		t.Errorf("cancelNote(\"\") = %q, want the default note", got)
+	}
+}
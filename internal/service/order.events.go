@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"order-service/internal/correlation"
+	"order-service/internal/database/db"
+	"order-service/internal/kafka"
+	"order-service/internal/tracing"
+)
+
+// Event types, sent in the event-type header. Topics are configurable, event types are not.
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderCancelled     = "order.cancelled"
+	EventOrderShipped       = "order.shipped"
+	EventOrderDelivered     = "order.delivered"
+	EventOrderRefunded      = "order.refunded"
+)
+
+// eventSchemaVersion is bumped whenever an event payload changes incompatibly
+const eventSchemaVersion = "2"
+
+// enqueueEvent writes an event to the outbox within the caller's transaction.
+// Events are keyed by order ID so all events of an order land on the same partition.
+func (s *OrderService) enqueueEvent(ctx context.Context, qtx *db.Queries, topic string, eventType string, orderId int32, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	eventHeaders := map[string]string{
+		kafka.HeaderEventType:     eventType,
+		kafka.HeaderSchemaVersion: eventSchemaVersion,
+		kafka.HeaderCorrelationID: correlation.FromContext(ctx),
+	}
+	// The relay publishes the event later; the stored trace context links it to this request
+	tracing.Inject(ctx, eventHeaders)
+
+	headers, err := json.Marshal(eventHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event headers: %w", err)
+	}
+
+	_, err = qtx.CreateOutboxEvent(ctx, db.CreateOutboxEventParams{
+		AggregateID: orderId,
+		Topic:       topic,
+		MessageKey:  strconv.Itoa(int(orderId)),
+		Headers:     headers,
+		Payload:     payload,
+	})
+	return err
+}
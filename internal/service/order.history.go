@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// Sources of a status change, recorded in the order history
+const (
+	SourceGRPC     = "GRPC"
+	SourceConsumer = "CONSUMER"
+	SourceJob      = "JOB"
+)
+
+// StatusChange describes who changed an order's status, from where and why
+type StatusChange struct {
+	Actor  string
+	Source string
+	// Reason is optional free text
+	Reason string
+}
+
+// recordStatusHistory appends a history entry within the caller's transaction.
+// from is empty for the entry written when the order is created.
+func (s *OrderService) recordStatusHistory(ctx context.Context, qtx *db.Queries, orderId int32, from string, to string, change StatusChange) error {
+	_, err := qtx.CreateOrderStatusHistory(ctx, statusHistoryParams(orderId, from, to, change))
+	return err
+}
+
+// statusHistoryParams builds a history entry; an empty from or reason is stored as NULL
+func statusHistoryParams(orderId int32, from string, to string, change StatusChange) db.CreateOrderStatusHistoryParams {
+	return db.CreateOrderStatusHistoryParams{
+		OrderID:    orderId,
+		FromStatus: pgtype.Text{String: from, Valid: from != ""},
+		ToStatus:   to,
+		Actor:      change.Actor,
+		Source:     change.Source,
+		Reason:     pgtype.Text{String: change.Reason, Valid: change.Reason != ""},
+	}
+}
+
+// GetOrderHistory returns the status history of an order, oldest first
+func (s *OrderService) GetOrderHistory(ctx context.Context, orderId int32) ([]db.OrderStatusHistory, error) {
+	if orderId <= 0 {
+		return nil, errors.NewFieldError(errors.CodeInvalidInput, "order_id", "order ID is required")
+	}
+
+	order, err := s.db.Queries.GetOrderByID(ctx, orderId)
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.ErrOrderNotFound
+		}
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	if err := authorizeOrder(ctx, &order); err != nil {
+		return nil, err
+	}
+
+	history, err := s.db.Queries.GetOrderStatusHistory(ctx, orderId)
+	if err != nil {
+		log.Printf("❌ Failed to get order history: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	return history, nil
+}
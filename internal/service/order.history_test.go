@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database/db"
+)
+
+func TestStatusHistoryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		change StatusChange
+		want   db.CreateOrderStatusHistoryParams
+	}{
+		{
+			name:   "order created",
+			to:     StatusPending,
+			change: StatusChange{Actor: "user:7", Source: SourceGRPC, Reason: "order created"},
+			want: db.CreateOrderStatusHistoryParams{
+				OrderID:  1,
+				ToStatus: StatusPending,
+				Actor:    "user:7",
+				Source:   SourceGRPC,
+				Reason:   pgtype.Text{String: "order created", Valid: true},
+			},
+		},
+		{
+			name:   "transition with a reason",
+			from:   StatusPending,
+			to:     StatusConfirmed,
+			change: StatusChange{Actor: paymentActor, Source: SourceConsumer, Reason: "payment.succeeded event e1"},
+			want: db.CreateOrderStatusHistoryParams{
+				OrderID:    1,
+				FromStatus: pgtype.Text{String: StatusPending, Valid: true},
+				ToStatus:   StatusConfirmed,
+				Actor:      paymentActor,
+				Source:     SourceConsumer,
+				Reason:     pgtype.Text{String: "payment.succeeded event e1", Valid: true},
+			},
+		},
+		{
+			name:   "transition without a reason",
+			from:   StatusConfirmed,
+			to:     StatusProcessing,
+			change: StatusChange{Actor: "system", Source: SourceJob},
+			want: db.CreateOrderStatusHistoryParams{
+				OrderID:    1,
+				FromStatus: pgtype.Text{String: StatusConfirmed, Valid: true},
+				ToStatus:   StatusProcessing,
+				Actor:      "system",
+				Source:     SourceJob,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusHistoryParams(1, tt.from, tt.to, tt.change)
+			if got != tt.want {
+				t.Errorf("statusHistoryParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+
+	"order-service/internal/errors"
+)
+
+// validateOrderItems checks that every line item has a product and a positive quantity
+func validateOrderItems(items []OrderItemParams) error {
+	for i, item := range items {
+		if item.ProductID <= 0 {
+			return errors.NewFieldError(errors.CodeInvalidProduct, fmt.Sprintf("products[%d].product_id", i), fmt.Sprintf("products[%d]: product ID is required", i))
+		}
+		if item.Quantity <= 0 {
+			return errors.NewFieldError(errors.CodeInvalidInput, fmt.Sprintf("products[%d].quantity", i), fmt.Sprintf("products[%d]: quantity must be positive", i))
+		}
+	}
+	return nil
+}
+
+// priceOrderItems converts each line item's unit price to minor units and returns
+// the unit prices together with the order subtotal
+func priceOrderItems(items []OrderItemParams, currency string) ([]Money, Money, error) {
+	prices := make([]Money, len(items))
+	subtotal := NewMoney(0, currency)
+
+	for i, item := range items {
+		field := fmt.Sprintf("products[%d].price", i)
+		var price Money
+		if item.PriceMinor != nil {
+			field += "_minor"
+			price = NewMoney(*item.PriceMinor, currency)
+		} else {
+			var err error
+			if price, err = MoneyFromFloat(item.Price, currency); err != nil {
+				return nil, Money{}, errors.NewFieldError(errors.CodeInvalidInput, field, fmt.Sprintf("products[%d]: %s", i, err.Error()))
+			}
+		}
+		if price.Amount <= 0 {
+			return nil, Money{}, errors.NewFieldError(errors.CodeInvalidInput, field, fmt.Sprintf("products[%d]: price must be positive", i))
+		}
+
+		lineTotal, err := price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, Money{}, err
+		}
+		if subtotal, err = subtotal.Add(lineTotal); err != nil {
+			return nil, Money{}, err
+		}
+
+		prices[i] = price
+	}
+
+	return prices, subtotal, nil
+}
+
+// clientOrderTotal returns the total the client expects and the request field it was
+// read from, preferring the minor-unit total over the deprecated decimal one
+func clientOrderTotal(params CreateOrderParams, currency string) (Money, string, error) {
+	if params.TotalAmountMinor != nil {
+		return NewMoney(*params.TotalAmountMinor, currency), "total_amount_minor", nil
+	}
+	total, err := MoneyFromFloat(params.TotalAmount, currency)
+	return total, "total_amount", err
+}
+
+// checkClientTotal reads the client's total and, unless ignoreClientTotal is set,
+// rejects it when it does not match the subtotal computed from the line items. The
+// returned total is nil when the request carried no usable total.
+func checkClientTotal(params CreateOrderParams, subtotal Money, ignoreClientTotal bool) (*Money, error) {
+	total, field, err := clientOrderTotal(params, subtotal.Currency)
+	if err != nil {
+		if ignoreClientTotal {
+			return nil, nil
+		}
+		return nil, errors.NewFieldError(errors.CodeInvalidInput, field, err.Error())
+	}
+	if !ignoreClientTotal && total.Amount != subtotal.Amount {
+		return nil, errors.NewFieldError(
+			errors.CodeInvalidInput,
+			field,
+			fmt.Sprintf("total amount %s does not match order subtotal %s", total, subtotal),
+		)
+	}
+	return &total, nil
+}
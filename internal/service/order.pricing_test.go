@@ -0,0 +1,123 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"order-service/internal/errors"
+)
+
+func TestValidateOrderItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []OrderItemParams
+		wantCode  string
+		wantField string
+	}{
+		{name: "valid", items: []OrderItemParams{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 5}}},
+		{name: "missing product", items: []OrderItemParams{{ProductID: 1, Quantity: 1}, {Quantity: 1}}, wantCode: errors.CodeInvalidProduct, wantField: "products[1].product_id"},
+		{name: "zero quantity", items: []OrderItemParams{{ProductID: 1}}, wantCode: errors.CodeInvalidInput, wantField: "products[0].quantity"},
+		{name: "negative quantity", items: []OrderItemParams{{ProductID: 1, Quantity: -2}}, wantCode: errors.CodeInvalidInput, wantField: "products[0].quantity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOrderItems(tt.items)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("validateOrderItems() error = %v", err)
+				}
+				return
+			}
+			assertFieldError(t, err, tt.wantCode, tt.wantField)
+		})
+	}
+}
+
+func TestPriceOrderItems(t *testing.T) {
+	negative := int64(-1)
+
+	tests := []struct {
+		name      string
+		items     []OrderItemParams
+		currency  string
+		wantTotal int64
+		wantField string
+	}{
+		{name: "sums line totals", items: []OrderItemParams{{ProductID: 1, Quantity: 3, Price: 2.5}, {ProductID: 2, Quantity: 1, Price: 0.99}}, currency: "USD", wantTotal: 849},
+		{name: "zero exponent", items: []OrderItemParams{{ProductID: 1, Quantity: 2, Price: 500}}, currency: "JPY", wantTotal: 1000},
+		{name: "zero price", items: []OrderItemParams{{ProductID: 1, Quantity: 1}}, currency: "USD", wantField: "products[0].price"},
+		{name: "price rounds to zero", items: []OrderItemParams{{ProductID: 1, Quantity: 1, Price: 0.004}}, currency: "USD", wantField: "products[0].price"},
+		{name: "negative minor price", items: []OrderItemParams{{ProductID: 1, Quantity: 1, PriceMinor: &negative}}, currency: "USD", wantField: "products[0].price_minor"},
+		{name: "NaN price", items: []OrderItemParams{{ProductID: 1, Quantity: 1, Price: math.NaN()}}, currency: "USD", wantField: "products[0].price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, total, err := priceOrderItems(tt.items, tt.currency)
+			if tt.wantField != "" {
+				assertFieldError(t, err, errors.CodeInvalidInput, tt.wantField)
+				return
+			}
+			if err != nil {
+				t.Fatalf("priceOrderItems() error = %v", err)
+			}
+			if total.Amount != tt.wantTotal || total.Currency != tt.currency {
+				t.Errorf("priceOrderItems() total = %v, want %d %s", total, tt.wantTotal, tt.currency)
+			}
+		})
+	}
+}
+
+func TestCheckClientTotal(t *testing.T) {
+	subtotal := NewMoney(849, "USD")
+	matching := int64(849)
+	mismatching := int64(1)
+
+	tests := []struct {
+		name      string
+		params    CreateOrderParams
+		ignore    bool
+		wantTotal *int64
+		wantField string
+	}{
+		{name: "matching minor total", params: CreateOrderParams{TotalAmountMinor: &matching}, wantTotal: &matching},
+		{name: "matching decimal total", params: CreateOrderParams{TotalAmount: 8.49}, wantTotal: &matching},
+		{name: "minor total wins over decimal", params: CreateOrderParams{TotalAmountMinor: &matching, TotalAmount: 1}, wantTotal: &matching},
+		{name: "mismatching minor total", params: CreateOrderParams{TotalAmountMinor: &mismatching}, wantField: "total_amount_minor"},
+		{name: "mismatching decimal total", params: CreateOrderParams{TotalAmount: 8.48}, wantField: "total_amount"},
+		{name: "missing total", params: CreateOrderParams{}, wantField: "total_amount"},
+		{name: "unreadable total", params: CreateOrderParams{TotalAmount: math.Inf(1)}, wantField: "total_amount"},
+		{name: "ignored mismatch", params: CreateOrderParams{TotalAmountMinor: &mismatching}, ignore: true, wantTotal: &mismatching},
+		{name: "ignored unreadable total", params: CreateOrderParams{TotalAmount: math.NaN()}, ignore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, err := checkClientTotal(tt.params, subtotal, tt.ignore)
+			if tt.wantField != "" {
+				assertFieldError(t, err, errors.CodeInvalidInput, tt.wantField)
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkClientTotal() error = %v", err)
+			}
+			if (total == nil) != (tt.wantTotal == nil) || (total != nil && total.Amount != *tt.wantTotal) {
+				t.Errorf("checkClientTotal() = %v, want %v", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// assertFieldError fails unless err is an OrderError with code and a single violation of field
+func assertFieldError(t *testing.T, err error, code, field string) {
+	t.Helper()
+
+	orderErr := errors.GetError(err)
+	if orderErr == nil || orderErr.ErrorCode != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+	if len(orderErr.Violations) != 1 || orderErr.Violations[0].Field != field {
+		t.Errorf("violations = %+v, want field %s", orderErr.Violations, field)
+	}
+}
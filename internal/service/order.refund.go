@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// Refund statuses
+const (
+	RefundStatusRequested = "REQUESTED"
+	RefundStatusApproved  = "APPROVED"
+	RefundStatusCompleted = "COMPLETED"
+	RefundStatusFailed    = "FAILED"
+)
+
+// refundTransitions lists, for every refund status, the statuses a refund may move to next.
+// COMPLETED and FAILED are terminal; failed refunds no longer count against the order.
+var refundTransitions = map[string][]string{
+	RefundStatusRequested: {RefundStatusApproved, RefundStatusFailed},
+	RefundStatusApproved:  {RefundStatusCompleted, RefundStatusFailed},
+	RefundStatusCompleted: {},
+	RefundStatusFailed:    {},
+}
+
+// IsValidRefundStatus reports whether status is a known refund status
+func IsValidRefundStatus(status string) bool {
+	_, ok := refundTransitions[status]
+	return ok
+}
+
+// CanTransitionRefund reports whether a refund in status from may move to status to
+func CanTransitionRefund(from, to string) bool {
+	for _, next := range refundTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type RefundItemParams struct {
+	OrderProductID int32
+	// Quantity is the number of units returned; zero for amount-only adjustments
+	Quantity int32
+	// AmountMinor is the amount refunded for the line; when zero it is the unit price times Quantity
+	AmountMinor int64
+}
+
+type CreateRefundParams struct {
+	OrderID int32
+	Reason  string
+	// Items are the order lines refunded; when empty everything not yet refunded is refunded
+	Items  []RefundItemParams
+	Actor  string
+	Source string
+	// ExpectedVersion, when set, must match the order's current version
+	ExpectedVersion int32
+}
+
+// CreateRefund requests a full or partial refund of an order. The refund starts as
+// REQUESTED and already counts against the refundable amount of each line. Requesting
+// it bumps the order version and is recorded in the order history.
+func (s *OrderService) CreateRefund(ctx context.Context, params CreateRefundParams) (*db.Refund, []db.RefundItem, error) {
+	if params.OrderID <= 0 {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "order_id", "order ID is required")
+	}
+	if params.Reason == "" {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "reason", "refund reason is required")
+	}
+	if params.ExpectedVersion < 0 {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "expected_version", "expected version cannot be negative")
+	}
+	seen := make(map[int32]bool, len(params.Items))
+	for i, item := range params.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.OrderProductID <= 0 {
+			return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, field+".order_product_id", "order product ID is required")
+		}
+		if seen[item.OrderProductID] {
+			return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, field+".order_product_id", "order product is listed more than once")
+		}
+		seen[item.OrderProductID] = true
+		if item.Quantity < 0 {
+			return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, field+".quantity", "quantity cannot be negative")
+		}
+		if item.AmountMinor < 0 {
+			return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, field+".amount_minor", "amount cannot be negative")
+		}
+		if item.Quantity == 0 && item.AmountMinor == 0 {
+			return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, field, "quantity or amount is required")
+		}
+	}
+	if err := authorizePrivileged(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to begin transaction: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
+
+	// Locking the order serializes refunds so concurrent requests cannot overspend it
+	order, err := qtx.GetOrderByIDForUpdate(ctx, params.OrderID)
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errors.ErrOrderNotFound
+		}
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	if err := checkVersion(&order, params.ExpectedVersion); err != nil {
+		return nil, nil, err
+	}
+	if !CanTransition(order.Status, StatusRefunded) {
+		return nil, nil, errors.NewOrderError(
+			errors.CodeInvalidStatus,
+			fmt.Sprintf("order in status %s cannot be refunded", order.Status),
+		)
+	}
+	// Orders cancelled before payment was captured have nothing to refund
+	if order.PaidAmountMinor <= 0 {
+		return nil, nil, errors.NewOrderError(
+			errors.CodeInvalidStatus,
+			fmt.Sprintf("order %d was never paid and cannot be refunded", order.ID),
+		)
+	}
+
+	items, err := s.planRefundItems(ctx, qtx, &order, params.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := NewMoney(0, order.Currency)
+	for _, item := range items {
+		if total, err = total.Add(NewMoney(item.AmountMinor, order.Currency)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if total.Amount == 0 {
+		return nil, nil, errors.NewOrderError(errors.CodeRefundExceedsPaid, "order is already fully refunded")
+	}
+
+	refund, err := qtx.CreateRefund(ctx, db.CreateRefundParams{
+		OrderID:     order.ID,
+		AmountMinor: total.Amount,
+		Currency:    order.Currency,
+		Reason:      params.Reason,
+		Actor:       params.Actor,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to create refund: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	refundItems := make([]db.RefundItem, len(items))
+	for i, item := range items {
+		refundItem, err := qtx.CreateRefundItem(ctx, db.CreateRefundItemParams{
+			RefundID:       refund.ID,
+			OrderProductID: item.OrderProductID,
+			Quantity:       item.Quantity,
+			AmountMinor:    item.AmountMinor,
+		})
+		if err != nil {
+			log.Printf("❌ Failed to create refund item: %v", err)
+			return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+		}
+		refundItems[i] = refundItem
+	}
+
+	if err := s.recordOrderChange(ctx, qtx, &order, StatusChange{
+		Actor:  params.Actor,
+		Source: params.Source,
+		Reason: fmt.Sprintf("refund %d requested", refund.ID),
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("❌ Failed to commit transaction: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	log.Printf("💸 Refund requested: ID=%d, OrderID=%d, Amount=%s", refund.ID, order.ID, total)
+	return &refund, refundItems, nil
+}
+
+// planRefundItems loads the order lines and what was already refunded of them, and
+// plans the requested refund against them with planRefund
+func (s *OrderService) planRefundItems(ctx context.Context, qtx *db.Queries, order *db.Order, requested []RefundItemParams) ([]RefundItemParams, error) {
+	products, err := qtx.GetOrderProductsByOrderID(ctx, order.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get order products: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	refunded, err := qtx.GetRefundedAmountsByOrderID(ctx, order.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get refunded amounts: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	return planRefund(order, products, refunded, requested)
+}
+
+// planRefund resolves the requested refund lines against the order, filling in
+// default amounts and rejecting anything above what is left to refund on each line
+// or, in total, above what was paid for the order
+func planRefund(order *db.Order, products []db.OrderProduct, refunded []db.GetRefundedAmountsByOrderIDRow, requested []RefundItemParams) ([]RefundItemParams, error) {
+	refundedByLine := make(map[int32]db.GetRefundedAmountsByOrderIDRow, len(refunded))
+	var refundedTotal int64
+	for _, r := range refunded {
+		refundedByLine[r.OrderProductID] = r
+		refundedTotal += r.RefundedAmountMinor
+	}
+
+	productsByID := make(map[int32]db.OrderProduct, len(products))
+	for _, p := range products {
+		productsByID[p.ID] = p
+	}
+
+	// remaining returns the quantity and amount of a line that can still be refunded
+	remaining := func(p db.OrderProduct) (int64, int64, error) {
+		lineTotal, err := NewMoney(p.PriceMinor, order.Currency).Mul(int64(p.Quantity))
+		if err != nil {
+			return 0, 0, err
+		}
+		r := refundedByLine[p.ID]
+		return int64(p.Quantity) - r.RefundedQuantity, lineTotal.Amount - r.RefundedAmountMinor, nil
+	}
+
+	var items []RefundItemParams
+	var requestedTotal int64
+	if len(requested) == 0 {
+		// A full refund covers whatever is left on every line
+		items = make([]RefundItemParams, 0, len(products))
+		for _, p := range products {
+			quantity, amount, err := remaining(p)
+			if err != nil {
+				return nil, err
+			}
+			if amount <= 0 {
+				continue
+			}
+			if quantity < 0 {
+				quantity = 0
+			}
+			items = append(items, RefundItemParams{
+				OrderProductID: p.ID,
+				Quantity:       int32(quantity),
+				AmountMinor:    amount,
+			})
+			requestedTotal += amount
+		}
+	} else {
+		items = make([]RefundItemParams, len(requested))
+		for i, item := range requested {
+			field := fmt.Sprintf("items[%d]", i)
+
+			p, ok := productsByID[item.OrderProductID]
+			if !ok {
+				return nil, errors.NewFieldError(
+					errors.CodeInvalidInput,
+					field+".order_product_id",
+					fmt.Sprintf("order product %d does not belong to order %d", item.OrderProductID, order.ID),
+				)
+			}
+
+			quantity, amount, err := remaining(p)
+			if err != nil {
+				return nil, err
+			}
+			if int64(item.Quantity) > quantity {
+				return nil, errors.NewFieldError(
+					errors.CodeRefundExceedsPaid,
+					field+".quantity",
+					fmt.Sprintf("only %d unit(s) of order product %d can still be refunded", max(quantity, 0), p.ID),
+				)
+			}
+
+			if item.AmountMinor == 0 {
+				lineAmount, err := NewMoney(p.PriceMinor, order.Currency).Mul(int64(item.Quantity))
+				if err != nil {
+					return nil, err
+				}
+				item.AmountMinor = lineAmount.Amount
+			}
+			if item.AmountMinor > amount {
+				return nil, errors.NewFieldError(
+					errors.CodeRefundExceedsPaid,
+					field+".amount_minor",
+					fmt.Sprintf("refund of %s exceeds the %s left on order product %d",
+						NewMoney(item.AmountMinor, order.Currency), NewMoney(max(amount, 0), order.Currency), p.ID),
+				)
+			}
+
+			requestedTotal += item.AmountMinor
+			items[i] = item
+		}
+	}
+
+	if refundedTotal+requestedTotal > order.PaidAmountMinor {
+		return nil, errors.NewOrderError(
+			errors.CodeRefundExceedsPaid,
+			fmt.Sprintf("refunds would exceed the %s paid for the order", NewMoney(order.PaidAmountMinor, order.Currency)),
+		)
+	}
+
+	return items, nil
+}
+
+type UpdateRefundStatusParams struct {
+	RefundID int32
+	Status   string
+	// FailureReason is recorded when Status is FAILED
+	FailureReason string
+	Actor         string
+	Source        string
+	// ExpectedVersion, when set, must match the current version of the refunded order
+	ExpectedVersion int32
+}
+
+// UpdateRefundStatus moves a refund through its lifecycle. Completing a refund emits
+// order.refunded, and once the order is refunded in full the order moves to REFUNDED.
+func (s *OrderService) UpdateRefundStatus(ctx context.Context, params UpdateRefundStatusParams) (*db.Refund, []db.RefundItem, error) {
+	if params.RefundID <= 0 {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "id", "refund ID is required")
+	}
+	if !IsValidRefundStatus(params.Status) {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidStatus, "status", "invalid refund status: "+params.Status)
+	}
+	if params.ExpectedVersion < 0 {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "expected_version", "expected version cannot be negative")
+	}
+	if err := authorizePrivileged(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to begin transaction: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
+
+	current, err := qtx.GetRefundByIDForUpdate(ctx, params.RefundID)
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errors.ErrRefundNotFound
+		}
+		log.Printf("❌ Failed to get refund: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	// The order is locked before the refund changes so completing it cannot race
+	// other changes to the order, and the caller's expected version can be checked
+	order, err := qtx.GetOrderByIDForUpdate(ctx, current.OrderID)
+	if err != nil {
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	if err := checkVersion(&order, params.ExpectedVersion); err != nil {
+		return nil, nil, err
+	}
+
+	if !CanTransitionRefund(current.Status, params.Status) {
+		return nil, nil, errors.NewOrderError(
+			errors.CodeInvalidStatus,
+			fmt.Sprintf("invalid refund status transition from %s to %s", current.Status, params.Status),
+		)
+	}
+
+	failureReason := pgtype.Text{}
+	if params.Status == RefundStatusFailed {
+		failureReason = pgtype.Text{String: params.FailureReason, Valid: params.FailureReason != ""}
+	}
+
+	refund, err := qtx.UpdateRefundStatus(ctx, db.UpdateRefundStatusParams{
+		ID:            current.ID,
+		Status:        params.Status,
+		FailureReason: failureReason,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update refund status: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	items, err := qtx.GetRefundItemsByRefundID(ctx, refund.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get refund items: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if refund.Status == RefundStatusCompleted {
+		change := StatusChange{
+			Actor:  params.Actor,
+			Source: params.Source,
+			Reason: fmt.Sprintf("refund %d completed", refund.ID),
+		}
+		if err := s.completeRefund(ctx, qtx, &order, &refund, items, params.ExpectedVersion, change); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("❌ Failed to commit transaction: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	log.Printf("💸 Refund updated: ID=%d, Status=%s -> %s, Actor=%s", refund.ID, current.Status, refund.Status, params.Actor)
+	return &refund, items, nil
+}
+
+// completeRefund emits order.refunded for a completed refund and moves the order,
+// locked by the caller, to REFUNDED once completed refunds cover the amount paid for it.
+// A partial refund keeps the status but still bumps the order version.
+func (s *OrderService) completeRefund(ctx context.Context, qtx *db.Queries, order *db.Order, refund *db.Refund, items []db.RefundItem, expectedVersion int32, change StatusChange) error {
+	refundedTotal, err := qtx.GetCompletedRefundTotal(ctx, order.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get refunded total: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	fullyRefunded := refundedInFull(order, refundedTotal)
+	details := refundEventDetails(refund, items, refundedTotal, fullyRefunded)
+
+	// The status change emits order.refunded itself
+	if fullyRefunded && CanTransition(order.Status, StatusRefunded) {
+		_, err := s.transitionStatus(ctx, qtx, order.ID, StatusRefunded, expectedVersion, change, details)
+		return err
+	}
+	if err := s.recordOrderChange(ctx, qtx, order, change); err != nil {
+		return err
+	}
+
+	event := map[string]interface{}{
+		"orderId":   order.ID,
+		"userId":    order.UserID,
+		"status":    order.Status,
+		"actor":     change.Actor,
+		"source":    change.Source,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	for k, v := range details {
+		event[k] = v
+	}
+
+	if err := s.enqueueEvent(ctx, qtx, s.cfg.KafkaTopicOrderRefunded, EventOrderRefunded, order.ID, event); err != nil {
+		log.Printf("❌ Failed to enqueue order.refunded event: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	return nil
+}
+
+// refundedInFull reports whether completed refunds of refundedTotal give back
+// everything paid for the order
+func refundedInFull(order *db.Order, refundedTotal int64) bool {
+	return order.PaidAmountMinor > 0 && refundedTotal >= order.PaidAmountMinor
+}
+
+// refundEventDetails returns the refund fields of the order.refunded event
+func refundEventDetails(refund *db.Refund, items []db.RefundItem, refundedTotal int64, fullyRefunded bool) map[string]interface{} {
+	lines := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		lines[i] = map[string]interface{}{
+			"orderProductId": item.OrderProductID,
+			"quantity":       item.Quantity,
+			"amountMinor":    item.AmountMinor,
+		}
+	}
+
+	return map[string]interface{}{
+		"refundId":           refund.ID,
+		"refundAmountMinor":  refund.AmountMinor,
+		"refundedTotalMinor": refundedTotal,
+		"currency":           refund.Currency,
+		"reason":             refund.Reason,
+		"refundItems":        lines,
+		"fullyRefunded":      fullyRefunded,
+	}
+}
+
+// GetOrderRefunds returns every refund of an order with its lines, oldest first
+func (s *OrderService) GetOrderRefunds(ctx context.Context, orderId int32) ([]db.Refund, []db.RefundItem, error) {
+	if orderId <= 0 {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "order_id", "order ID is required")
+	}
+
+	order, err := s.db.Queries.GetOrderByID(ctx, orderId)
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errors.ErrOrderNotFound
+		}
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	if err := authorizeOrder(ctx, &order); err != nil {
+		return nil, nil, err
+	}
+
+	refunds, err := s.db.Queries.GetRefundsByOrderID(ctx, orderId)
+	if err != nil {
+		log.Printf("❌ Failed to get refunds: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	items, err := s.db.Queries.GetRefundItemsByOrderID(ctx, orderId)
+	if err != nil {
+		log.Printf("❌ Failed to get refund items: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	return refunds, items, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+func TestPlanRefund(t *testing.T) {
+	// Two lines: 2 x 10.00 and 1 x 5.00, paid in full
+	order := db.Order{ID: 1, Currency: "USD", TotalAmountMinor: 2500, PaidAmountMinor: 2500}
+	products := []db.OrderProduct{
+		{ID: 11, OrderID: 1, Quantity: 2, PriceMinor: 1000},
+		{ID: 12, OrderID: 1, Quantity: 1, PriceMinor: 500},
+	}
+
+	tests := []struct {
+		name      string
+		order     db.Order
+		refunded  []db.GetRefundedAmountsByOrderIDRow
+		requested []RefundItemParams
+		want      []RefundItemParams
+		wantCode  string
+		wantField string
+	}{
+		{
+			name:  "full refund",
+			order: order,
+			want: []RefundItemParams{
+				{OrderProductID: 11, Quantity: 2, AmountMinor: 2000},
+				{OrderProductID: 12, Quantity: 1, AmountMinor: 500},
+			},
+		},
+		{
+			name:     "full refund of what is left",
+			order:    order,
+			refunded: []db.GetRefundedAmountsByOrderIDRow{{OrderProductID: 11, RefundedQuantity: 1, RefundedAmountMinor: 1000}},
+			want: []RefundItemParams{
+				{OrderProductID: 11, Quantity: 1, AmountMinor: 1000},
+				{OrderProductID: 12, Quantity: 1, AmountMinor: 500},
+			},
+		},
+		{
+			name:  "full refund of a fully refunded order",
+			order: order,
+			refunded: []db.GetRefundedAmountsByOrderIDRow{
+				{OrderProductID: 11, RefundedQuantity: 2, RefundedAmountMinor: 2000},
+				{OrderProductID: 12, RefundedQuantity: 1, RefundedAmountMinor: 500},
+			},
+			want: []RefundItemParams{},
+		},
+		{
+			name:      "quantity defaults the amount",
+			order:     order,
+			requested: []RefundItemParams{{OrderProductID: 11, Quantity: 1}},
+			want:      []RefundItemParams{{OrderProductID: 11, Quantity: 1, AmountMinor: 1000}},
+		},
+		{
+			name:      "amount-only adjustment",
+			order:     order,
+			requested: []RefundItemParams{{OrderProductID: 12, AmountMinor: 200}},
+			want:      []RefundItemParams{{OrderProductID: 12, AmountMinor: 200}},
+		},
+		{
+			name:      "unknown line",
+			order:     order,
+			requested: []RefundItemParams{{OrderProductID: 99, Quantity: 1}},
+			wantCode:  errors.CodeInvalidInput,
+			wantField: "items[0].order_product_id",
+		},
+		{
+			name:      "more units than left",
+			order:     order,
+			refunded:  []db.GetRefundedAmountsByOrderIDRow{{OrderProductID: 11, RefundedQuantity: 2, RefundedAmountMinor: 2000}},
+			requested: []RefundItemParams{{OrderProductID: 11, Quantity: 1}},
+			wantCode:  errors.CodeRefundExceedsPaid,
+			wantField: "items[0].quantity",
+		},
+		{
+			name:      "more than left on the line",
+			order:     order,
+			requested: []RefundItemParams{{OrderProductID: 12, AmountMinor: 501}},
+			wantCode:  errors.CodeRefundExceedsPaid,
+			wantField: "items[0].amount_minor",
+		},
+		{
+			name:      "partial payment caps a line refund",
+			order:     db.Order{ID: 1, Currency: "USD", TotalAmountMinor: 2500, PaidAmountMinor: 1500},
+			refunded:  []db.GetRefundedAmountsByOrderIDRow{{OrderProductID: 11, RefundedQuantity: 1, RefundedAmountMinor: 1000}},
+			requested: []RefundItemParams{{OrderProductID: 11, Quantity: 1}},
+			wantCode:  errors.CodeRefundExceedsPaid,
+		},
+		{
+			name:     "partial payment caps a full refund",
+			order:    db.Order{ID: 1, Currency: "USD", TotalAmountMinor: 2500, PaidAmountMinor: 1500},
+			wantCode: errors.CodeRefundExceedsPaid,
+		},
+		{
+			name:      "unpaid order",
+			order:     db.Order{ID: 1, Currency: "USD", TotalAmountMinor: 2500},
+			requested: []RefundItemParams{{OrderProductID: 12, AmountMinor: 1}},
+			wantCode:  errors.CodeRefundExceedsPaid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planRefund(&tt.order, products, tt.refunded, tt.requested)
+			if tt.wantCode != "" {
+				if errors.GetErrorCode(err) != tt.wantCode {
+					t.Fatalf("planRefund() error = %v, want %s", err, tt.wantCode)
+				}
+				if tt.wantField != "" {
+					orderErr := errors.GetError(err)
+					if len(orderErr.Violations) != 1 || orderErr.Violations[0].Field != tt.wantField {
+						t.Errorf("planRefund() violations = %+v, want field %s", orderErr.Violations, tt.wantField)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planRefund() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planRefund() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundedInFull(t *testing.T) {
+	tests := []struct {
+		name          string
+		paid          int64
+		refundedTotal int64
+		want          bool
+	}{
+		{name: "partly refunded", paid: 2500, refundedTotal: 2000, want: false},
+		{name: "refunded in full", paid: 2500, refundedTotal: 2500, want: true},
+		{name: "partial payment refunded", paid: 1500, refundedTotal: 1500, want: true},
+		{name: "unpaid", paid: 0, refundedTotal: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		order := db.Order{TotalAmountMinor: 2500, PaidAmountMinor: tt.paid}
+		if got := refundedInFull(&order, tt.refundedTotal); got != tt.want {
+			t.Errorf("%s: refundedInFull() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRefundEventDetails(t *testing.T) {
+	refund := db.Refund{ID: 7, AmountMinor: 1200, Currency: "USD", Reason: "damaged"}
+	items := []db.RefundItem{
+		{OrderProductID: 11, Quantity: 1, AmountMinor: 1000},
+		{OrderProductID: 12, AmountMinor: 200},
+	}
+
+	got := refundEventDetails(&refund, items, 2500, true)
+
+	want := map[string]interface{}{
+		"refundId":           int32(7),
+		"refundAmountMinor":  int64(1200),
+		"refundedTotalMinor": int64(2500),
+		"currency":           "USD",
+		"reason":             "damaged",
+		"refundItems": []map[string]interface{}{
+			{"orderProductId": int32(11), "quantity": int32(1), "amountMinor": int64(1000)},
+			{"orderProductId": int32(12), "quantity": int32(0), "amountMinor": int64(200)},
+		},
+		"fullyRefunded": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("refundEventDetails() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"order-service/internal/database"
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+// Sort fields accepted by SearchOrders
+const (
+	SortByCreatedAt   = "created_at"
+	SortByUpdatedAt   = "updated_at"
+	SortByTotalAmount = "total_amount"
+	SortByID          = "id"
+)
+
+// Sort directions accepted by SearchOrders
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+var sortColumns = map[string]database.OrderSortColumn{
+	SortByCreatedAt:   database.SortCreatedAt,
+	SortByUpdatedAt:   database.SortUpdatedAt,
+	SortByTotalAmount: database.SortTotalAmount,
+	SortByID:          database.SortID,
+}
+
+type SearchOrdersParams struct {
+	// UserID and ProductID are ignored when zero
+	UserID    int32
+	ProductID int32
+	// Statuses matches any of the given statuses; empty matches all
+	Statuses []string
+	// CreatedFrom is inclusive and CreatedTo exclusive; nil leaves the bound open
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// MinTotalMinor and MaxTotalMinor are inclusive bounds in minor units; nil leaves the bound open
+	MinTotalMinor *int64
+	MaxTotalMinor *int64
+	// SortBy defaults to created_at and SortDirection to desc
+	SortBy        string
+	SortDirection string
+	// Cursor pagination is available when sorting by created_at; other sorts page by offset
+	Page PageParams
+}
+
+// SearchOrders returns a page of orders matching every given filter
+func (s *OrderService) SearchOrders(ctx context.Context, params SearchOrdersParams) ([]db.Order, PageInfo, error) {
+	if err := authorizePrivileged(ctx); err != nil {
+		return nil, PageInfo{}, err
+	}
+	if params.UserID < 0 {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "user_id", "user ID cannot be negative")
+	}
+	if params.ProductID < 0 {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "product_id", "product ID cannot be negative")
+	}
+	for _, status := range params.Statuses {
+		if !IsValidStatus(status) {
+			return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidStatus, "statuses", "invalid order status: "+status)
+		}
+	}
+	if params.CreatedFrom != nil && params.CreatedTo != nil && !params.CreatedFrom.Before(*params.CreatedTo) {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "created_to", "created_to must be after created_from")
+	}
+	if params.MinTotalMinor != nil && params.MaxTotalMinor != nil && *params.MinTotalMinor > *params.MaxTotalMinor {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "max_total_minor", "max_total_minor must not be below min_total_minor")
+	}
+
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = SortByCreatedAt
+	}
+	sortColumn, ok := sortColumns[sortBy]
+	if !ok {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "sort_by", "invalid sort field: "+params.SortBy)
+	}
+	direction := params.SortDirection
+	if direction == "" {
+		direction = SortDesc
+	}
+	if direction != SortAsc && direction != SortDesc {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "sort_direction", "invalid sort direction: "+params.SortDirection)
+	}
+
+	// Only (created_at, id) positions can be expressed as a cursor
+	keyset := sortBy == SortByCreatedAt && !params.Page.useOffset()
+	if params.Page.Cursor != "" && !keyset {
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "cursor", "cursor pagination requires sorting by created_at")
+	}
+
+	info := PageInfo{Limit: params.Page.limit()}
+	offset := int32(0)
+	if !keyset {
+		info.Page = max(params.Page.Page, 1)
+		offset = (info.Page - 1) * info.Limit
+	}
+
+	// The cursor is only valid for the sort order and filters it was issued for
+	filters := params
+	filters.SortBy, filters.SortDirection, filters.Page = "", "", PageParams{}
+	scope := newCursorScope(sortBy+"_"+direction, filters)
+	cursor, err := decodeOrderCursor(params.Page.Cursor, scope)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	search := database.OrderSearch{
+		UserID:        params.UserID,
+		ProductID:     params.ProductID,
+		Statuses:      params.Statuses,
+		CreatedFrom:   params.CreatedFrom,
+		CreatedTo:     params.CreatedTo,
+		MinTotalMinor: params.MinTotalMinor,
+		MaxTotalMinor: params.MaxTotalMinor,
+		SortBy:        sortColumn,
+		Descending:    direction == SortDesc,
+		Limit:         info.Limit,
+		Offset:        offset,
+	}
+	if cursor != nil {
+		search.AfterValue = cursor.CreatedAt
+		search.AfterID = cursor.ID
+	}
+	if keyset {
+		// One extra row tells whether there is a next page
+		search.Limit++
+	}
+
+	orders, err := database.SearchOrders(ctx, s.db, search)
+	if err != nil {
+		log.Printf("❌ Failed to search orders: %v", err)
+		return nil, PageInfo{}, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	if keyset {
+		orders = keysetPage(orders, &info, scope)
+	}
+
+	if !keyset || params.Page.IncludeTotal {
+		total, err := database.CountOrders(ctx, s.db, search)
+		if err != nil {
+			log.Printf("❌ Failed to count orders: %v", err)
+			return nil, PageInfo{}, errors.Wrap(errors.CodeDatabaseError, err)
+		}
+		info.setTotal(total)
+	}
+
+	return orders, info, nil
+}
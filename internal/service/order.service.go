@@ -2,46 +2,91 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"log"
-	"math"
+	"order-service/internal/config"
 	"order-service/internal/database"
 	"order-service/internal/database/db"
 	"order-service/internal/errors"
-	"order-service/internal/kafka"
+	"order-service/internal/metrics"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type OrderService struct {
-	db       *database.DB
-	producer *kafka.Producer
-	topic    string
+	db  *database.DB
+	cfg *config.Config
 }
 
-func NewOrderService(db *database.DB, producer *kafka.Producer, topic string) *OrderService {
+func NewOrderService(db *database.DB, cfg *config.Config) *OrderService {
 	return &OrderService{
-		db:       db,
-		producer: producer,
-		topic:    topic,
+		db:  db,
+		cfg: cfg,
 	}
 }
 
+type OrderItemParams struct {
+	ProductID int32
+	Quantity  int32
+	// PriceMinor is the unit price in minor units of the order currency. When nil the
+	// deprecated decimal Price, in major units, is converted instead.
+	PriceMinor *int64
+	Price      float64
+}
+
 type CreateOrderParams struct {
-	UserID      int32
-	TotalAmount float64
-	Products    []struct {
-		ProductID int32
-		Quantity  int32
-		Price     float64
-	}
+	UserID int32
+	// Currency is an ISO 4217 code; the configured default is used when empty
+	Currency string
+	// TotalAmountMinor is the client's view of the total in minor units; the stored total
+	// is always computed from Products. When nil the deprecated decimal TotalAmount is used.
+	TotalAmountMinor *int64
+	TotalAmount      float64
+	Products         []OrderItemParams
+	// IdempotencyKey is optional; retries with the same key return the original order
+	IdempotencyKey string
+	Actor          string
+	Source         string
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, params CreateOrderParams) (*db.Order, []db.OrderProduct, error) {
 	// Validate input
 	if params.UserID <= 0 {
-		return nil, nil, errors.NewOrderError(errors.CodeInvalidInput, "user ID is required")
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "user_id", "user ID is required")
 	}
 	if len(params.Products) == 0 {
-		return nil, nil, errors.NewOrderError(errors.CodeInvalidInput, "at least one product is required")
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "products", "at least one product is required")
+	}
+	if len(params.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "idempotency-key", "idempotency key is too long")
+	}
+	if err := validateOrderItems(params.Products); err != nil {
+		return nil, nil, err
+	}
+	if err := authorizeUser(ctx, params.UserID); err != nil {
+		return nil, nil, err
+	}
+
+	currency := params.Currency
+	if currency == "" {
+		currency = s.cfg.DefaultCurrency
+	}
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prices, totalAmount, err := priceOrderItems(params.Products, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientTotal, err := checkClientTotal(params, totalAmount, s.cfg.IgnoreClientTotal)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	tx, err := s.db.Begin(ctx)
@@ -53,15 +98,41 @@ func (s *OrderService) CreateOrder(ctx context.Context, params CreateOrderParams
 
 	qtx := s.db.Queries.WithTx(tx)
 
+	var idempotencyKey *db.IdempotencyKey
+	if params.IdempotencyKey != "" {
+		requestHash, err := hashCreateOrderRequest(params.UserID, currency, params.Products, prices, clientTotal)
+		if err != nil {
+			return nil, nil, errors.Wrap(errors.CodeInternalError, err)
+		}
+		key, existingOrder, existingProducts, err := s.reserveIdempotencyKey(ctx, qtx, params, requestHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existingOrder != nil {
+			return existingOrder, existingProducts, nil
+		}
+		idempotencyKey = key
+	}
+
 	order, err := qtx.CreateOrder(ctx, db.CreateOrderParams{
-		UserID:      params.UserID,
-		Status:      "PENDING",
-		TotalAmount: params.TotalAmount,
+		UserID:           params.UserID,
+		Status:           StatusPending,
+		TotalAmountMinor: totalAmount.Amount,
+		Currency:         totalAmount.Currency,
 	})
 
 	if err != nil {
 		log.Printf("❌ Failed to create order: %v", err)
-		return nil, nil, errors.NewOrderError(errors.CodeOrderCreateFailed, "failed to create order")
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if err := s.recordStatusHistory(ctx, qtx, order.ID, "", order.Status, StatusChange{
+		Actor:  params.Actor,
+		Source: params.Source,
+		Reason: "order created",
+	}); err != nil {
+		log.Printf("❌ Failed to record order status history: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
 	}
 
 	// Create order products
@@ -69,138 +140,282 @@ func (s *OrderService) CreateOrder(ctx context.Context, params CreateOrderParams
 
 	for i, p := range params.Products {
 		product, err := qtx.CreateOrderProduct(ctx, db.CreateOrderProductParams{
-			ProductID: p.ProductID,
-			OrderID:   order.ID,
-			Quantity:  p.Quantity,
-			Price:     p.Price,
+			ProductID:  p.ProductID,
+			OrderID:    order.ID,
+			Quantity:   p.Quantity,
+			PriceMinor: prices[i].Amount,
 		})
 
 		if err != nil {
 			log.Printf("❌ Failed to create order product: %v", err)
-			return nil, nil, errors.NewOrderError(errors.CodeOrderCreateFailed, "failed to create order product")
+			return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
 		}
 
 		products[i] = product
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		log.Printf("❌ Failed to commit transaction: %v", err)
-		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	if idempotencyKey != nil {
+		if err := qtx.SetIdempotencyKeyOrder(ctx, db.SetIdempotencyKeyOrderParams{
+			ID:      idempotencyKey.ID,
+			OrderID: pgtype.Int4{Int32: order.ID, Valid: true},
+		}); err != nil {
+			log.Printf("❌ Failed to store idempotency key: %v", err)
+			return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+		}
 	}
 
-	log.Printf("✅ Order created: ID=%d, UserID=%d", order.ID, order.UserID)
-
 	event := map[string]interface{}{
-		"orderId":     order.ID,
-		"userId":      order.UserID,
-		"status":      order.Status,
-		"items":       s.mapProductsToItems(products),
-		"totalAmount": order.TotalAmount,
+		"orderId":          order.ID,
+		"userId":           order.UserID,
+		"status":           order.Status,
+		"items":            s.mapProductsToItems(products, order.Currency),
+		"totalAmountMinor": order.TotalAmountMinor,
+		"currency":         order.Currency,
+		// Deprecated: decimal amount kept for consumers that have not moved to minor units
+		"totalAmount": NewMoney(order.TotalAmountMinor, order.Currency).Float64(),
 		"timestamp":   time.Now().Format(time.RFC3339),
 	}
 
-	if err = s.producer.Emit(s.topic, event); err != nil {
-		log.Printf("⚠️ Failed to emit order.created event: %v", err)
-		// Don't fail the order creation if Kafka fails
-	} else {
-		log.Printf("📨 Emitted order.created event to Kafka")
+	// The event is stored with the order and published by the outbox relay
+	if err := s.enqueueEvent(ctx, qtx, s.cfg.KafkaTopicOrderCreated, EventOrderCreated, order.ID, event); err != nil {
+		log.Printf("❌ Failed to enqueue order.created event: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("❌ Failed to commit transaction: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	log.Printf("✅ Order created: ID=%d, UserID=%d", order.ID, order.UserID)
+	metrics.OrdersCreated.WithLabelValues(order.Status, order.Currency).Inc()
+	metrics.OrderValue.WithLabelValues(order.Currency).Add(float64(order.TotalAmountMinor))
+
 	return &order, products, nil
 }
 
+// GetOrder returns an order with its products, fetched in a single query
 func (s *OrderService) GetOrder(ctx context.Context, orderId int32) (*db.Order, []db.OrderProduct, error) {
 	if orderId <= 0 {
-		return nil, nil, errors.NewOrderError(errors.CodeInvalidInput, "order ID is required")
+		return nil, nil, errors.NewFieldError(errors.CodeInvalidInput, "id", "order ID is required")
 	}
 
-	order, err := s.db.Queries.GetOrderByID(ctx, orderId)
+	row, err := s.db.Queries.GetOrderWithProducts(ctx, orderId)
 	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errors.ErrOrderNotFound
+		}
 		log.Printf("❌ Failed to get order: %v", err)
-		return nil, nil, errors.ErrOrderNotFound
+		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	var products []db.OrderProduct
+	if err := json.Unmarshal(row.Products, &products); err != nil {
+		log.Printf("❌ Failed to decode order products: %v", err)
+		return nil, nil, errors.Wrap(errors.CodeInternalError, err)
+	}
+
+	order := db.Order{
+		ID:               row.ID,
+		UserID:           row.UserID,
+		Status:           row.Status,
+		TotalAmountMinor: row.TotalAmountMinor,
+		Currency:         row.Currency,
+		Version:          row.Version,
+		PaidAmountMinor:  row.PaidAmountMinor,
+		CreatedAt:        row.CreatedAt,
+		UpdatedAt:        row.UpdatedAt,
+	}
+	if err := authorizeOrder(ctx, &order); err != nil {
+		return nil, nil, err
 	}
 
-	products, err := s.db.Queries.GetOrderProductsByOrderID(ctx, orderId)
+	return &order, products, nil
+}
+
+// GetProductsForOrders returns the products of every given order, keyed by order ID,
+// using one query for the whole set
+func (s *OrderService) GetProductsForOrders(ctx context.Context, orders []db.Order) (map[int32][]db.OrderProduct, error) {
+	if len(orders) == 0 {
+		return map[int32][]db.OrderProduct{}, nil
+	}
+
+	ids := make([]int32, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+
+	products, err := s.db.Queries.GetOrderProductsByOrderIDs(ctx, ids)
 	if err != nil {
 		log.Printf("❌ Failed to get order products: %v", err)
-		return nil, nil, errors.Wrap(errors.CodeDatabaseError, err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
 	}
 
-	return &order, products, nil
+	return groupProductsByOrder(products, len(orders)), nil
+}
+
+// groupProductsByOrder keys products by their order ID, keeping their order within
+// each order. Orders without products have no entry.
+func groupProductsByOrder(products []db.OrderProduct, orderCount int) map[int32][]db.OrderProduct {
+	byOrder := make(map[int32][]db.OrderProduct, orderCount)
+	for _, p := range products {
+		byOrder[p.OrderID] = append(byOrder[p.OrderID], p)
+	}
+	return byOrder
 }
 
-func (s *OrderService) GetOrdersByUserId(ctx context.Context, userId int32, limit int32, page int32) ([]db.Order, int32, int32, error) {
+// GetOrdersByUserId lists a user's orders, newest first. Pages are walked with an
+// opaque cursor; requests with a page number use the legacy offset pagination.
+func (s *OrderService) GetOrdersByUserId(ctx context.Context, userId int32, page PageParams) ([]db.Order, PageInfo, error) {
 	if userId <= 0 {
-		return nil, 0, 0, errors.NewOrderError(errors.CodeInvalidInput, "user ID is required")
+		return nil, PageInfo{}, errors.NewFieldError(errors.CodeInvalidInput, "user_id", "user ID is required")
 	}
-	if limit <= 0 {
-		limit = 10 // Default limit
+	if err := authorizeUser(ctx, userId); err != nil {
+		return nil, PageInfo{}, err
 	}
-	if page <= 0 {
-		page = 1 // Default page
+
+	info := PageInfo{Limit: page.limit()}
+
+	if page.useOffset() {
+		info.Page = page.Page
+
+		orders, err := s.db.Queries.GetOrdersByUserID(ctx, db.GetOrdersByUserIDParams{
+			UserID: userId,
+			Limit:  info.Limit,
+			Offset: (page.Page - 1) * info.Limit,
+		})
+		if err != nil {
+			log.Printf("❌ Failed to get orders: %v", err)
+			return nil, PageInfo{}, errors.Wrap(errors.CodeDatabaseError, err)
+		}
+
+		total, err := s.db.Queries.GetOrdersByUserIDCount(ctx, userId)
+		if err != nil {
+			log.Printf("❌ Failed to get orders count: %v", err)
+			return nil, PageInfo{}, errors.Wrap(errors.CodeDatabaseError, err)
+		}
+		info.setTotal(total)
+
+		return orders, info, nil
 	}
 
-	orders, err := s.db.Queries.GetOrdersByUserID(ctx, db.GetOrdersByUserIDParams{
-		UserID: userId,
-		Limit:  limit,
-		Offset: (page - 1) * limit,
-	})
+	scope := newCursorScope(SortByCreatedAt+"_"+SortDesc, map[string]int32{"user_id": userId})
+	cursor, err := decodeOrderCursor(page.Cursor, scope)
 	if err != nil {
-		log.Printf("❌ Failed to get orders: %v", err)
-		return nil, 0, 0, errors.Wrap(errors.CodeDatabaseError, err)
+		return nil, PageInfo{}, err
 	}
 
-	total, err := s.db.Queries.GetOrdersByUserIDCount(ctx, userId)
+	// One extra row tells whether there is a next page
+	var orders []db.Order
+	if cursor == nil {
+		orders, err = s.db.Queries.GetOrdersByUserID(ctx, db.GetOrdersByUserIDParams{
+			UserID: userId,
+			Limit:  info.Limit + 1,
+		})
+	} else {
+		orders, err = s.db.Queries.GetOrdersByUserIDAfter(ctx, db.GetOrdersByUserIDAfterParams{
+			UserID:          userId,
+			CursorCreatedAt: pgtype.Timestamp{Time: cursor.CreatedAt, Valid: true},
+			CursorID:        cursor.ID,
+			Limit:           info.Limit + 1,
+		})
+	}
 	if err != nil {
-		log.Printf("❌ Failed to get orders count: %v", err)
-		return nil, 0, 0, errors.Wrap(errors.CodeDatabaseError, err)
+		log.Printf("❌ Failed to get orders: %v", err)
+		return nil, PageInfo{}, errors.Wrap(errors.CodeDatabaseError, err)
 	}
+	orders = keysetPage(orders, &info, scope)
 
-	totalPages := int32(math.Ceil(float64(total) / float64(limit)))
+	if page.IncludeTotal {
+		total, err := s.db.Queries.GetOrdersByUserIDCount(ctx, userId)
+		if err != nil {
+			log.Printf("❌ Failed to get orders count: %v", err)
+			return nil, PageInfo{}, errors.Wrap(errors.CodeDatabaseError, err)
+		}
+		info.setTotal(total)
+	}
 
-	return orders, int32(total), totalPages, nil
+	return orders, info, nil
 }
 
-// Valid order statuses
-var validStatuses = map[string]bool{
-	"PENDING":    true,
-	"CONFIRMED":  true,
-	"PROCESSING": true,
-	"SHIPPED":    true,
-	"DELIVERED":  true,
-	"CANCELLED":  true,
-	"REFUNDED":   true,
+type UpdateOrderStatusParams struct {
+	OrderID int32
+	Status  string
+	// Actor identifies who requested the change and is carried on the emitted events
+	Actor  string
+	Source string
+	// Reason is optional free text recorded in the order history
+	Reason string
+	// ExpectedVersion, when set, must match the order's current version
+	ExpectedVersion int32
 }
 
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderId int32, status string) (*db.Order, error) {
-	if orderId <= 0 {
-		return nil, errors.NewOrderError(errors.CodeInvalidInput, "order ID is required")
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, params UpdateOrderStatusParams) (*db.Order, error) {
+	if params.OrderID <= 0 {
+		return nil, errors.NewFieldError(errors.CodeInvalidInput, "id", "order ID is required")
+	}
+	if params.ExpectedVersion < 0 {
+		return nil, errors.NewFieldError(errors.CodeInvalidInput, "expected_version", "expected version cannot be negative")
+	}
+	if !IsValidStatus(params.Status) {
+		return nil, errors.NewFieldError(errors.CodeInvalidStatus, "status", "invalid order status: "+params.Status)
 	}
-	if !validStatuses[status] {
-		return nil, errors.NewOrderError(errors.CodeInvalidStatus, "invalid order status: "+status)
+	if params.Status == StatusRefunded {
+		// Refunds must be recorded; the order moves to REFUNDED when its refunds complete
+		return nil, errors.NewFieldError(errors.CodeInvalidStatus, "status", "orders are refunded through CreateRefund")
+	}
+	if err := authorizePrivileged(ctx); err != nil {
+		return nil, err
 	}
 
-	order, err := s.db.Queries.UpdateOrderStatus(ctx, db.UpdateOrderStatusParams{
-		ID:     orderId,
-		Status: status,
-	})
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to begin transaction: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
 
+	var order *db.Order
+	if params.Status == StatusCancelled {
+		// Cancellations are always recorded; callers wanting a specific reason use CancelOrder
+		order, err = s.cancelOrder(ctx, qtx, CancelOrderParams{
+			OrderID:         params.OrderID,
+			ReasonCode:      CancelReasonOther,
+			Note:            cancelNote(params.Reason),
+			Actor:           params.Actor,
+			Source:          params.Source,
+			ExpectedVersion: params.ExpectedVersion,
+		}, nil)
+	} else {
+		order, err = s.transitionStatus(ctx, qtx, params.OrderID, params.Status, params.ExpectedVersion, StatusChange{
+			Actor:  params.Actor,
+			Source: params.Source,
+			Reason: params.Reason,
+		}, nil)
+	}
 	if err != nil {
-		log.Printf("❌ Failed to update order status: %v", err)
-		return nil, errors.ErrOrderUpdateFailed
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("❌ Failed to commit transaction: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
 	}
 
-	log.Printf("✅ Order status updated: ID=%d, Status=%s", orderId, status)
-	return &order, nil
+	return order, nil
 }
 
-func (s *OrderService) mapProductsToItems(products []db.OrderProduct) []map[string]interface{} {
+func (s *OrderService) mapProductsToItems(products []db.OrderProduct, currency string) []map[string]interface{} {
 	items := make([]map[string]interface{}, len(products))
 	for i, p := range products {
 		items[i] = map[string]interface{}{
-			"productId": p.ProductID,
-			"quantity":  p.Quantity,
-			"price":     p.Price,
+			"productId":  p.ProductID,
+			"quantity":   p.Quantity,
+			"priceMinor": p.PriceMinor,
+			// Deprecated: decimal price kept for consumers that have not moved to minor units
+			"price": NewMoney(p.PriceMinor, currency).Float64(),
 		}
 	}
 
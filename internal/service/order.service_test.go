@@ -0,0 +1,47 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"order-service/internal/database/db"
+)
+
+func TestGroupProductsByOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []db.OrderProduct
+		want     map[int32][]db.OrderProduct
+	}{
+		{
+			name:     "no products",
+			products: []db.OrderProduct{},
+			want:     map[int32][]db.OrderProduct{},
+		},
+		{
+			name: "products of several orders",
+			products: []db.OrderProduct{
+				{ID: 11, OrderID: 1, ProductID: 100},
+				{ID: 21, OrderID: 2, ProductID: 200},
+				{ID: 12, OrderID: 1, ProductID: 101},
+			},
+			want: map[int32][]db.OrderProduct{
+				1: {{ID: 11, OrderID: 1, ProductID: 100}, {ID: 12, OrderID: 1, ProductID: 101}},
+				2: {{ID: 21, OrderID: 2, ProductID: 200}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupProductsByOrder(tt.products, 3)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupProductsByOrder() = %+v, want %+v", got, tt.want)
+			}
+			// An order without products reads as an empty list
+			if products := got[3]; len(products) != 0 {
+				t.Errorf("products of order 3 = %+v, want none", products)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+	"order-service/internal/metrics"
+)
+
+// Order statuses
+const (
+	StatusPending    = "PENDING"
+	StatusConfirmed  = "CONFIRMED"
+	StatusProcessing = "PROCESSING"
+	StatusShipped    = "SHIPPED"
+	StatusDelivered  = "DELIVERED"
+	StatusCancelled  = "CANCELLED"
+	StatusRefunded   = "REFUNDED"
+)
+
+// statusTransitions lists, for every status, the statuses an order may move to next.
+// Statuses only ever move forward; REFUNDED is terminal.
+var statusTransitions = map[string][]string{
+	StatusPending:    {StatusConfirmed, StatusCancelled},
+	StatusConfirmed:  {StatusProcessing, StatusCancelled},
+	StatusProcessing: {StatusShipped, StatusCancelled},
+	StatusShipped:    {StatusDelivered},
+	StatusDelivered:  {StatusRefunded},
+	StatusCancelled:  {StatusRefunded},
+	StatusRefunded:   {},
+}
+
+// IsValidStatus reports whether status is a known order status
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// CanTransition reports whether an order in status from may move to status to
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVersion fails with a version conflict when the caller expected the order at a
+// different version. An expected version of zero skips the check.
+func checkVersion(order *db.Order, expectedVersion int32) error {
+	if expectedVersion != 0 && order.Version != expectedVersion {
+		return errors.NewOrderError(
+			errors.CodeVersionConflict,
+			fmt.Sprintf("order %d is at version %d, expected %d", order.ID, order.Version, expectedVersion),
+		)
+	}
+	return nil
+}
+
+// recordOrderChange records a change to an order, locked by the caller, that keeps its
+// status, such as a refund being requested. The version moves on so callers holding
+// the previous version get a conflict, and the change is appended to the order history.
+func (s *OrderService) recordOrderChange(ctx context.Context, qtx *db.Queries, order *db.Order, change StatusChange) error {
+	updated, err := qtx.BumpOrderVersion(ctx, db.BumpOrderVersionParams{
+		ID:      order.ID,
+		Version: order.Version,
+	})
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return errors.ErrVersionConflict
+		}
+		log.Printf("❌ Failed to update order version: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if err := s.recordStatusHistory(ctx, qtx, updated.ID, updated.Status, updated.Status, change); err != nil {
+		log.Printf("❌ Failed to record order status history: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	*order = updated
+	return nil
+}
+
+// transitionStatus moves an order to a new status within the caller's transaction.
+// The order row is locked so the transition is checked against the status actually
+// being replaced, and the status change events are written to the outbox.
+// The change is appended to the order history, and extra is merged into the
+// status-specific event (e.g. order.cancelled) payload. A non-zero expectedVersion
+// makes the transition fail if the order was modified since the caller read it.
+func (s *OrderService) transitionStatus(ctx context.Context, qtx *db.Queries, orderId int32, status string, expectedVersion int32, change StatusChange, extra map[string]interface{}) (*db.Order, error) {
+	current, err := qtx.GetOrderByIDForUpdate(ctx, orderId)
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.ErrOrderNotFound
+		}
+		log.Printf("❌ Failed to get order: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if err := checkVersion(&current, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	if !CanTransition(current.Status, status) {
+		return nil, errors.NewOrderError(
+			errors.CodeInvalidStatus,
+			fmt.Sprintf("invalid status transition from %s to %s", current.Status, status),
+		)
+	}
+
+	order, err := qtx.UpdateOrderStatus(ctx, db.UpdateOrderStatusParams{
+		ID:      orderId,
+		Status:  status,
+		Version: current.Version,
+	})
+
+	if err != nil {
+		if stderrors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.ErrVersionConflict
+		}
+		log.Printf("❌ Failed to update order status: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if err := s.recordStatusHistory(ctx, qtx, order.ID, current.Status, order.Status, change); err != nil {
+		log.Printf("❌ Failed to record order status history: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	event := statusChangedEvent(current.Status, &order, change, time.Now())
+
+	if err := s.enqueueEvent(ctx, qtx, s.cfg.KafkaTopicOrderStatusChanged, EventOrderStatusChanged, order.ID, event); err != nil {
+		log.Printf("❌ Failed to enqueue order.status_changed event: %v", err)
+		return nil, errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	if topic, eventType := s.statusTopic(status); topic != "" {
+		if err := s.enqueueEvent(ctx, qtx, topic, eventType, order.ID, withExtra(event, extra)); err != nil {
+			log.Printf("❌ Failed to enqueue %s event: %v", eventType, err)
+			return nil, errors.Wrap(errors.CodeDatabaseError, err)
+		}
+	}
+
+	log.Printf("✅ Order status updated: ID=%d, Status=%s -> %s, Actor=%s, Source=%s", orderId, current.Status, status, change.Actor, change.Source)
+	// Counted once written; the caller rolling back afterwards is rare enough to ignore
+	metrics.OrderStatusTransitions.WithLabelValues(current.Status, status).Inc()
+	return &order, nil
+}
+
+// statusChangedEvent returns the order.status_changed payload for an order that just
+// moved from previous to its current status
+func statusChangedEvent(previous string, order *db.Order, change StatusChange, at time.Time) map[string]interface{} {
+	event := map[string]interface{}{
+		"orderId":        order.ID,
+		"userId":         order.UserID,
+		"previousStatus": previous,
+		"status":         order.Status,
+		"version":        order.Version,
+		"actor":          change.Actor,
+		"source":         change.Source,
+		"timestamp":      at.Format(time.RFC3339),
+	}
+	if change.Reason != "" {
+		event["statusReason"] = change.Reason
+	}
+	return event
+}
+
+// withExtra returns a copy of event with the fields of extra added, as sent on the
+// status-specific topics
+func withExtra(event, extra map[string]interface{}) map[string]interface{} {
+	specific := make(map[string]interface{}, len(event)+len(extra))
+	for k, v := range event {
+		specific[k] = v
+	}
+	for k, v := range extra {
+		specific[k] = v
+	}
+	return specific
+}
+
+// statusTopic returns the dedicated topic and event type for a status, or empty
+// strings if the status only produces the generic status changed event
+func (s *OrderService) statusTopic(status string) (string, string) {
+	switch status {
+	case StatusCancelled:
+		return s.cfg.KafkaTopicOrderCancelled, EventOrderCancelled
+	case StatusShipped:
+		return s.cfg.KafkaTopicOrderShipped, EventOrderShipped
+	case StatusDelivered:
+		return s.cfg.KafkaTopicOrderDelivered, EventOrderDelivered
+	case StatusRefunded:
+		return s.cfg.KafkaTopicOrderRefunded, EventOrderRefunded
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusPending, StatusConfirmed, true},
+		{StatusPending, StatusCancelled, true},
+		{StatusPending, StatusShipped, false},
+		{StatusPending, StatusRefunded, false},
+		{StatusConfirmed, StatusProcessing, true},
+		{StatusConfirmed, StatusCancelled, true},
+		{StatusConfirmed, StatusPending, false},
+		{StatusProcessing, StatusShipped, true},
+		{StatusProcessing, StatusCancelled, true},
+		{StatusShipped, StatusDelivered, true},
+		{StatusShipped, StatusCancelled, false},
+		{StatusDelivered, StatusRefunded, true},
+		{StatusDelivered, StatusCancelled, false},
+		{StatusCancelled, StatusRefunded, true},
+		{StatusCancelled, StatusPending, false},
+		{StatusRefunded, StatusPending, false},
+		{StatusRefunded, StatusRefunded, false},
+		{StatusPending, StatusPending, false},
+		{"UNKNOWN", StatusConfirmed, false},
+		{StatusPending, "UNKNOWN", false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for status := range statusTransitions {
+		if !IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%q) = false, want true", status)
+		}
+	}
+	for _, status := range []string{"", "pending", "UNKNOWN"} {
+		if IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%q) = true, want false", status)
+		}
+	}
+}
+
+func TestStatusTopic(t *testing.T) {
+	s := &OrderService{cfg: &config.Config{
+		KafkaTopicOrderCancelled: "orders.cancelled.v1",
+		KafkaTopicOrderShipped:   "orders.shipped.v1",
+		KafkaTopicOrderDelivered: "orders.delivered.v1",
+		KafkaTopicOrderRefunded:  "orders.refunded.v1",
+	}}
+
+	tests := []struct {
+		status        string
+		wantTopic     string
+		wantEventType string
+	}{
+		{StatusCancelled, "orders.cancelled.v1", EventOrderCancelled},
+		{StatusShipped, "orders.shipped.v1", EventOrderShipped},
+		{StatusDelivered, "orders.delivered.v1", EventOrderDelivered},
+		{StatusRefunded, "orders.refunded.v1", EventOrderRefunded},
+		{StatusConfirmed, "", ""},
+		{StatusProcessing, "", ""},
+		{StatusPending, "", ""},
+	}
+
+	for _, tt := range tests {
+		topic, eventType := s.statusTopic(tt.status)
+		if topic != tt.wantTopic || eventType != tt.wantEventType {
+			t.Errorf("statusTopic(%s) = %q, %q, want %q, %q", tt.status, topic, eventType, tt.wantTopic, tt.wantEventType)
+		}
+	}
+}
+
+func TestStatusChangedEvent(t *testing.T) {
+	at := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	order := db.Order{ID: 42, UserID: 7, Status: StatusShipped, Version: 4}
+
+	tests := []struct {
+		name   string
+		change StatusChange
+		want   map[string]interface{}
+	}{
+		{
+			name:   "without reason",
+			change: StatusChange{Actor: "admin", Source: SourceGRPC},
+			want: map[string]interface{}{
+				"orderId":        int32(42),
+				"userId":         int32(7),
+				"previousStatus": StatusProcessing,
+				"status":         StatusShipped,
+				"version":        int32(4),
+				"actor":          "admin",
+				"source":         SourceGRPC,
+				"timestamp":      "2025-03-04T05:06:07Z",
+			},
+		},
+		{
+			name:   "with reason",
+			change: StatusChange{Actor: "admin", Source: SourceGRPC, Reason: "handed to carrier"},
+			want: map[string]interface{}{
+				"orderId":        int32(42),
+				"userId":         int32(7),
+				"previousStatus": StatusProcessing,
+				"status":         StatusShipped,
+				"version":        int32(4),
+				"actor":          "admin",
+				"source":         SourceGRPC,
+				"timestamp":      "2025-03-04T05:06:07Z",
+				"statusReason":   "handed to carrier",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusChangedEvent(StatusProcessing, &order, tt.change, at)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statusChangedEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithExtra(t *testing.T) {
+	event := map[string]interface{}{"orderId": 1, "status": StatusCancelled}
+	extra := map[string]interface{}{"reasonCode": "CUSTOMER_REQUEST", "status": "overridden"}
+
+	got := withExtra(event, extra)
+
+	want := map[string]interface{}{"orderId": 1, "status": "overridden", "reasonCode": "CUSTOMER_REQUEST"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withExtra() = %v, want %v", got, want)
+	}
+	if event["status"] != StatusCancelled || len(event) != 2 {
+		t.Errorf("withExtra() modified the generic event: %v", event)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	order := db.Order{ID: 1, Version: 3}
+
+	tests := []struct {
+		name     string
+		expected int32
+		wantCode string
+	}{
+		{name: "no expected version", expected: 0},
+		{name: "current version", expected: 3},
+		{name: "stale version", expected: 2, wantCode: errors.CodeVersionConflict},
+		{name: "future version", expected: 4, wantCode: errors.CodeVersionConflict},
+	}
+
+	for _, tt := range tests {
+		err := checkVersion(&order, tt.expected)
+		if tt.wantCode == "" {
+			if err != nil {
+				t.Errorf("%s: checkVersion() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if errors.GetErrorCode(err) != tt.wantCode {
+			t.Errorf("%s: checkVersion() error = %v, want %s", tt.name, err, tt.wantCode)
+		}
+	}
+}
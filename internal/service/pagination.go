@@ -0,0 +1,135 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"time"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+const (
+	// DefaultPageLimit is used when a list request does not set a limit
+	DefaultPageLimit = 10
+	// MaxPageLimit caps the page size of every list request
+	MaxPageLimit = 100
+)
+
+// PageParams selects a page of a listing. A non-empty Cursor continues a keyset
+// listing; otherwise a Page > 0 selects the legacy LIMIT/OFFSET pagination.
+type PageParams struct {
+	Limit  int32
+	Page   int32
+	Cursor string
+	// IncludeTotal counts all matches; legacy page requests always count
+	IncludeTotal bool
+}
+
+// PageInfo describes the page returned by a listing
+type PageInfo struct {
+	Limit int32
+	// Page is only set for legacy page requests
+	Page int32
+	// Total and TotalPages are only set when HasTotal is true
+	Total      int32
+	TotalPages int32
+	HasTotal   bool
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// useOffset reports whether the request asked for legacy LIMIT/OFFSET pagination
+func (p PageParams) useOffset() bool {
+	return p.Cursor == "" && p.Page > 0
+}
+
+// limit returns the requested page size, defaulted and capped to MaxPageLimit
+func (p PageParams) limit() int32 {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return p.Limit
+}
+
+// setTotal fills in the total number of matches and the resulting page count
+func (i *PageInfo) setTotal(total int64) {
+	i.HasTotal = true
+	i.Total = int32(total)
+	i.TotalPages = int32(math.Ceil(float64(total) / float64(i.Limit)))
+}
+
+// orderCursor is the position after the last order of a page, in (created_at, id)
+// order. It also records the listing it was issued for, so it cannot be used to
+// continue a listing with a different sort order or different filters.
+type orderCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int32     `json:"id"`
+	Sort      string    `json:"s"`
+	Filters   string    `json:"f"`
+}
+
+// cursorScope identifies a listing by its sort order and a digest of its filters
+type cursorScope struct {
+	Sort    string
+	Filters string
+}
+
+// newCursorScope returns the scope of a listing sorted by sort and filtered by
+// filters, which must encode to JSON
+func newCursorScope(sort string, filters interface{}) cursorScope {
+	encoded, _ := json.Marshal(filters)
+	digest := sha256.Sum256(encoded)
+	return cursorScope{Sort: sort, Filters: base64.RawURLEncoding.EncodeToString(digest[:12])}
+}
+
+// encodeOrderCursor returns the opaque cursor pointing after order in the listing scope
+func encodeOrderCursor(order db.Order, scope cursorScope) string {
+	payload, _ := json.Marshal(orderCursor{
+		CreatedAt: order.CreatedAt.Time,
+		ID:        order.ID,
+		Sort:      scope.Sort,
+		Filters:   scope.Filters,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodeOrderCursor parses a cursor returned by encodeOrderCursor and checks that it
+// was issued for the same listing scope. An empty cursor decodes to nil, meaning
+// the first page.
+func decodeOrderCursor(cursor string, scope cursorScope) (*orderCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	invalid := errors.NewFieldError(errors.CodeInvalidInput, "cursor", "invalid cursor")
+
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c orderCursor
+	if err := json.Unmarshal(payload, &c); err != nil || c.ID <= 0 || c.CreatedAt.IsZero() {
+		return nil, invalid
+	}
+	if c.Sort != scope.Sort || c.Filters != scope.Filters {
+		return nil, errors.NewFieldError(errors.CodeInvalidInput, "cursor", "cursor was issued for a different sort order or filters")
+	}
+
+	return &c, nil
+}
+
+// keysetPage trims the extra row fetched to detect a following page and sets
+// the cursor for it
+func keysetPage(orders []db.Order, info *PageInfo, scope cursorScope) []db.Order {
+	if len(orders) > int(info.Limit) {
+		orders = orders[:info.Limit]
+		info.NextCursor = encodeOrderCursor(orders[len(orders)-1], scope)
+	}
+	return orders
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+)
+
+func testOrder(id int32, createdAt time.Time) db.Order {
+	return db.Order{ID: id, CreatedAt: pgtype.Timestamp{Time: createdAt, Valid: true}}
+}
+
+func TestDecodeOrderCursor(t *testing.T) {
+	createdAt := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	scope := newCursorScope("created_at_desc", map[string]int32{"user_id": 1})
+	valid := encodeOrderCursor(testOrder(42, createdAt), scope)
+
+	tests := []struct {
+		name    string
+		cursor  string
+		scope   cursorScope
+		want    *orderCursor
+		wantErr bool
+	}{
+		{name: "empty", cursor: "", scope: scope, want: nil},
+		{
+			name:   "round trip",
+			cursor: valid,
+			scope:  scope,
+			want:   &orderCursor{CreatedAt: createdAt, ID: 42, Sort: scope.Sort, Filters: scope.Filters},
+		},
+		{name: "not base64", cursor: "!!!", scope: scope, wantErr: true},
+		{name: "not json", cursor: base64.RawURLEncoding.EncodeToString([]byte("nope")), scope: scope, wantErr: true},
+		{name: "missing id", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2025-03-04T05:06:07Z"}`)), scope: scope, wantErr: true},
+		{name: "missing time", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"id":42}`)), scope: scope, wantErr: true},
+		{
+			name:    "different sort",
+			cursor:  valid,
+			scope:   newCursorScope("created_at_asc", map[string]int32{"user_id": 1}),
+			wantErr: true,
+		},
+		{
+			name:    "different filters",
+			cursor:  valid,
+			scope:   newCursorScope("created_at_desc", map[string]int32{"user_id": 2}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeOrderCursor(tt.cursor, tt.scope)
+			if tt.wantErr {
+				if errors.GetErrorCode(err) != errors.CodeInvalidInput {
+					t.Fatalf("decodeOrderCursor() error = %v, want %s", err, errors.CodeInvalidInput)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeOrderCursor() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("decodeOrderCursor() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && (!got.CreatedAt.Equal(tt.want.CreatedAt) || got.ID != tt.want.ID ||
+				got.Sort != tt.want.Sort || got.Filters != tt.want.Filters) {
+				t.Errorf("decodeOrderCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetPage(t *testing.T) {
+	scope := newCursorScope("created_at_desc", nil)
+	start := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	orders := make([]db.Order, 4)
+	for i := range orders {
+		orders[i] = testOrder(int32(10-i), start.Add(-time.Duration(i)*time.Hour))
+	}
+
+	tests := []struct {
+		name       string
+		rows       int
+		limit      int32
+		wantLen    int
+		wantCursor bool
+	}{
+		{name: "empty", rows: 0, limit: 3, wantLen: 0},
+		{name: "partial page", rows: 2, limit: 3, wantLen: 2},
+		{name: "exactly full", rows: 3, limit: 3, wantLen: 3},
+		{name: "extra row", rows: 4, limit: 3, wantLen: 3, wantCursor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := PageInfo{Limit: tt.limit}
+			got := keysetPage(orders[:tt.rows], &info, scope)
+
+			if len(got) != tt.wantLen {
+				t.Fatalf("keysetPage() returned %d orders, want %d", len(got), tt.wantLen)
+			}
+			if (info.NextCursor != "") != tt.wantCursor {
+				t.Fatalf("keysetPage() NextCursor = %q, want cursor: %v", info.NextCursor, tt.wantCursor)
+			}
+			if !tt.wantCursor {
+				return
+			}
+
+			cursor, err := decodeOrderCursor(info.NextCursor, scope)
+			if err != nil {
+				t.Fatalf("decodeOrderCursor(NextCursor) error = %v", err)
+			}
+			last := got[len(got)-1]
+			if cursor.ID != last.ID || !cursor.CreatedAt.Equal(last.CreatedAt.Time) {
+				t.Errorf("NextCursor points at (%s, %d), want last order (%s, %d)",
+					cursor.CreatedAt, cursor.ID, last.CreatedAt.Time, last.ID)
+			}
+		})
+	}
+}
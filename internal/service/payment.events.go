@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"order-service/internal/correlation"
+	"order-service/internal/database"
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+	"order-service/internal/kafka"
+)
+
+// Payment event types consumed from the payment service
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+)
+
+// paymentActor is recorded as the actor of status changes driven by payment events
+const paymentActor = "payment-service"
+
+type PaymentEvent struct {
+	EventID   string `json:"eventId"`
+	EventType string `json:"eventType"`
+	OrderID   int32  `json:"orderId"`
+	PaymentID string `json:"paymentId"`
+	// AmountMinor is the amount captured, in minor units of the order currency.
+	// payment.succeeded events without it paid the order total.
+	AmountMinor *int64 `json:"amountMinor,omitempty"`
+	Reason      string `json:"reason"`
+	// Topic the event was consumed from
+	Topic string `json:"-"`
+}
+
+// NewProcessedEventsJanitor returns a janitor that forgets processed events once they
+// are older than retention. Redeliveries of an event must arrive within the retention
+// to still be recognised as duplicates.
+func NewProcessedEventsJanitor(db *database.DB, retention, interval time.Duration) *Janitor {
+	return &Janitor{
+		description: "processed events",
+		interval:    interval,
+		purge: func(ctx context.Context) (int64, error) {
+			cutoff := pgtype.Timestamp{Time: time.Now().UTC().Add(-retention), Valid: true}
+			return db.Queries.DeleteProcessedEventsBefore(ctx, cutoff)
+		},
+	}
+}
+
+// HandlePaymentMessage decodes a payment event consumed from Kafka and applies it.
+// Event type and ID are taken from the standard headers when present.
+func (s *OrderService) HandlePaymentMessage(ctx context.Context, msg kafka.Message) error {
+	event, err := decodePaymentMessage(msg)
+	if err != nil {
+		return kafka.Permanent(err)
+	}
+	if correlationID := msg.Headers[kafka.HeaderCorrelationID]; correlationID != "" {
+		ctx = correlation.WithID(ctx, correlationID)
+	}
+
+	err = s.ApplyPaymentEvent(ctx, event)
+	if errors.GetErrorCode(err) == errors.CodeInvalidInput {
+		return kafka.Permanent(err)
+	}
+	return err
+}
+
+// decodePaymentMessage decodes the payment event carried by msg. The event type and ID
+// headers take precedence over the payload; without either, the topic stands in for
+// the type and the message position for the ID.
+func decodePaymentMessage(msg kafka.Message) (PaymentEvent, error) {
+	var event PaymentEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return PaymentEvent{}, fmt.Errorf("invalid payment event: %w", err)
+	}
+
+	event.Topic = msg.Topic
+	if eventType := msg.Headers[kafka.HeaderEventType]; eventType != "" {
+		event.EventType = eventType
+	} else if event.EventType == "" {
+		event.EventType = msg.Topic
+	}
+	if eventID := msg.Headers[kafka.HeaderEventID]; eventID != "" {
+		event.EventID = eventID
+	} else if event.EventID == "" {
+		event.EventID = fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+	}
+	return event, nil
+}
+
+// paymentEventStatus validates a payment event and returns the order status it moves
+// the order to
+func paymentEventStatus(event PaymentEvent) (string, error) {
+	var status string
+	switch event.EventType {
+	case EventPaymentSucceeded:
+		status = StatusConfirmed
+	case EventPaymentFailed:
+		status = StatusCancelled
+	default:
+		return "", errors.NewOrderError(errors.CodeInvalidInput, "unsupported payment event type: "+event.EventType)
+	}
+	if event.OrderID <= 0 {
+		return "", errors.NewOrderError(errors.CodeInvalidInput, "order ID is required")
+	}
+	if event.EventID == "" {
+		return "", errors.NewOrderError(errors.CodeInvalidInput, "event ID is required")
+	}
+	if event.AmountMinor != nil && *event.AmountMinor < 0 {
+		return "", errors.NewOrderError(errors.CodeInvalidInput, "payment amount cannot be negative")
+	}
+	return status, nil
+}
+
+// capturesPayment reports whether applying event, which ended with err, leaves money
+// captured for the order. A payment.succeeded event captured the amount even when the
+// order could no longer be confirmed, e.g. because it was cancelled before the
+// payment went through, and that amount must remain refundable.
+func capturesPayment(event PaymentEvent, err error) bool {
+	if event.EventType != EventPaymentSucceeded {
+		return false
+	}
+	return err == nil || errors.GetErrorCode(err) == errors.CodeInvalidStatus
+}
+
+// ApplyPaymentEvent confirms or cancels an order from a payment event. Each event is
+// applied at most once; events that no longer apply to the order are recorded and ignored.
+func (s *OrderService) ApplyPaymentEvent(ctx context.Context, event PaymentEvent) error {
+	status, err := paymentEventStatus(event)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to begin transaction: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
+
+	processed, err := qtx.MarkEventProcessed(ctx, db.MarkEventProcessedParams{
+		EventID: event.EventID,
+		Topic:   event.Topic,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to record processed event: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+	if processed == 0 {
+		log.Printf("🔁 Skipping already processed %s event %s", event.EventType, event.EventID)
+		return nil
+	}
+
+	extra := map[string]interface{}{
+		"paymentId": event.PaymentID,
+	}
+	if event.Reason != "" {
+		extra["reason"] = event.Reason
+	}
+
+	var order *db.Order
+	if status == StatusCancelled {
+		_, err = s.cancelOrder(ctx, qtx, CancelOrderParams{
+			OrderID:    event.OrderID,
+			ReasonCode: CancelReasonPaymentFailed,
+			Note:       event.Reason,
+			Actor:      paymentActor,
+			Source:     SourceConsumer,
+		}, extra)
+	} else {
+		order, err = s.transitionStatus(ctx, qtx, event.OrderID, status, 0, StatusChange{
+			Actor:  paymentActor,
+			Source: SourceConsumer,
+			Reason: fmt.Sprintf("%s event %s", event.EventType, event.EventID),
+		}, extra)
+	}
+	captured := capturesPayment(event, err)
+	if err != nil {
+		switch errors.GetErrorCode(err) {
+		case errors.CodeInvalidStatus, errors.CodeOrderNotFound:
+			// Retrying cannot help; keep the event recorded as processed
+			log.Printf("⚠️ Ignoring %s event %s for order %d: %v", event.EventType, event.EventID, event.OrderID, err)
+		default:
+			return err
+		}
+	}
+
+	if captured {
+		if order == nil {
+			// The transition was refused, so the order was not returned
+			current, err := qtx.GetOrderByIDForUpdate(ctx, event.OrderID)
+			if err != nil {
+				log.Printf("❌ Failed to get order: %v", err)
+				return errors.Wrap(errors.CodeDatabaseError, err)
+			}
+			order = &current
+		}
+		if err := s.recordPayment(ctx, qtx, order, event.AmountMinor); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("❌ Failed to commit transaction: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	return nil
+}
+
+// recordPayment stores the amount captured for an order, which caps its refunds.
+// A nil amount means the order total was paid.
+func (s *OrderService) recordPayment(ctx context.Context, qtx *db.Queries, order *db.Order, amountMinor *int64) error {
+	paid := capturedAmount(order, amountMinor)
+
+	if err := qtx.RecordOrderPayment(ctx, db.RecordOrderPaymentParams{
+		ID:              order.ID,
+		PaidAmountMinor: paid,
+	}); err != nil {
+		log.Printf("❌ Failed to record order payment: %v", err)
+		return errors.Wrap(errors.CodeDatabaseError, err)
+	}
+
+	order.PaidAmountMinor = paid
+	log.Printf("💳 Payment recorded: OrderID=%d, Amount=%s", order.ID, NewMoney(paid, order.Currency))
+	return nil
+}
+
+// capturedAmount returns the amount a payment.succeeded event captured for order:
+// the amount it carries, or the order total when it carries none
+func capturedAmount(order *db.Order, amountMinor *int64) int64 {
+	if amountMinor != nil {
+		return *amountMinor
+	}
+	return order.TotalAmountMinor
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"testing"
+
+	"order-service/internal/database/db"
+	"order-service/internal/errors"
+	"order-service/internal/kafka"
+)
+
+func TestDecodePaymentMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		msg           kafka.Message
+		wantEventType string
+		wantEventID   string
+		wantErr       bool
+	}{
+		{
+			name: "headers take precedence",
+			msg: kafka.Message{
+				Topic:   "payments",
+				Value:   []byte(`{"eventId":"body-id","eventType":"payment.failed","orderId":1}`),
+				Headers: map[string]string{kafka.HeaderEventType: EventPaymentSucceeded, kafka.HeaderEventID: "header-id"},
+			},
+			wantEventType: EventPaymentSucceeded,
+			wantEventID:   "header-id",
+		},
+		{
+			name: "payload fields without headers",
+			msg: kafka.Message{
+				Topic: "payments",
+				Value: []byte(`{"eventId":"body-id","eventType":"payment.failed","orderId":1}`),
+			},
+			wantEventType: EventPaymentFailed,
+			wantEventID:   "body-id",
+		},
+		{
+			name: "topic and position fallbacks",
+			msg: kafka.Message{
+				Topic:     EventPaymentSucceeded,
+				Value:     []byte(`{"orderId":1}`),
+				Partition: 2,
+				Offset:    42,
+			},
+			wantEventType: EventPaymentSucceeded,
+			wantEventID:   "payment.succeeded-2-42",
+		},
+		{
+			name:    "invalid JSON",
+			msg:     kafka.Message{Topic: "payments", Value: []byte(`{"orderId":`)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePaymentMessage(tt.msg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodePaymentMessage() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodePaymentMessage() error = %v", err)
+			}
+			if got.EventType != tt.wantEventType || got.EventID != tt.wantEventID || got.Topic != tt.msg.Topic {
+				t.Errorf("decodePaymentMessage() = type %q, id %q, topic %q; want %q, %q, %q",
+					got.EventType, got.EventID, got.Topic, tt.wantEventType, tt.wantEventID, tt.msg.Topic)
+			}
+		})
+	}
+}
+
+func TestPaymentEventStatus(t *testing.T) {
+	negative := int64(-1)
+
+	tests := []struct {
+		name       string
+		event      PaymentEvent
+		wantStatus string
+		wantCode   string
+	}{
+		{
+			name:       "succeeded confirms",
+			event:      PaymentEvent{EventID: "e1", EventType: EventPaymentSucceeded, OrderID: 1},
+			wantStatus: StatusConfirmed,
+		},
+		{
+			name:       "failed cancels",
+			event:      PaymentEvent{EventID: "e1", EventType: EventPaymentFailed, OrderID: 1},
+			wantStatus: StatusCancelled,
+		},
+		{
+			name:     "unsupported type",
+			event:    PaymentEvent{EventID: "e1", EventType: "payment.pending", OrderID: 1},
+			wantCode: errors.CodeInvalidInput,
+		},
+		{
+			name:     "missing order",
+			event:    PaymentEvent{EventID: "e1", EventType: EventPaymentSucceeded},
+			wantCode: errors.CodeInvalidInput,
+		},
+		{
+			name:     "missing event ID",
+			event:    PaymentEvent{EventType: EventPaymentSucceeded, OrderID: 1},
+			wantCode: errors.CodeInvalidInput,
+		},
+		{
+			name:     "negative amount",
+			event:    PaymentEvent{EventID: "e1", EventType: EventPaymentSucceeded, OrderID: 1, AmountMinor: &negative},
+			wantCode: errors.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := paymentEventStatus(tt.event)
+			if tt.wantCode != "" {
+				if errors.GetErrorCode(err) != tt.wantCode {
+					t.Fatalf("paymentEventStatus() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("paymentEventStatus() error = %v", err)
+			}
+			if got != tt.wantStatus {
+				t.Errorf("paymentEventStatus() = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCapturesPayment(t *testing.T) {
+	succeeded := PaymentEvent{EventType: EventPaymentSucceeded}
+	failed := PaymentEvent{EventType: EventPaymentFailed}
+
+	tests := []struct {
+		name  string
+		event PaymentEvent
+		err   error
+		want  bool
+	}{
+		{name: "order confirmed", event: succeeded, want: true},
+		{
+			// The order was cancelled before the capture arrived; the money was
+			// still taken and has to be refundable
+			name:  "cancelled before capture",
+			event: succeeded,
+			err:   errors.NewOrderError(errors.CodeInvalidStatus, "cannot change status from CANCELLED to CONFIRMED"),
+			want:  true,
+		},
+		{
+			name:  "order not found",
+			event: succeeded,
+			err:   errors.NewOrderError(errors.CodeOrderNotFound, "order not found"),
+		},
+		{
+			name:  "database error",
+			event: succeeded,
+			err:   errors.NewOrderError(errors.CodeDatabaseError, "database error"),
+		},
+		{name: "payment failed", event: failed},
+		{
+			name:  "payment failed for a cancelled order",
+			event: failed,
+			err:   errors.NewOrderError(errors.CodeInvalidStatus, "order is already cancelled"),
+		},
+	}
+
+	for _, tt := range tests {
+		if got := capturesPayment(tt.event, tt.err); got != tt.want {
+			t.Errorf("%s: capturesPayment() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCapturedAmount(t *testing.T) {
+	order := db.Order{TotalAmountMinor: 2500}
+	partial := int64(1500)
+
+	if got := capturedAmount(&order, nil); got != 2500 {
+		t.Errorf("capturedAmount() without an amount = %d, want the order total 2500", got)
+	}
+	if got := capturedAmount(&order, &partial); got != 1500 {
+		t.Errorf("capturedAmount() = %d, want 1500", got)
+	}
+}